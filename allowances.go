@@ -0,0 +1,194 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/modules/erc20"
+	"chain-lens/report"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// allowanceReport allowances 子命令输出的 JSON 结构
+type allowanceReport struct {
+	ChainID    int64             `json:"chain_id"`
+	Block      uint64            `json:"block"`
+	Allowances []erc20.Allowance `json:"allowances"`
+}
+
+// runAllowances allowances 子命令：审计钱包对各个 spender 的 ERC20 授权
+//
+//	chain-lens allowances -tokens 0xUSDC,0xUSDT -spenders 0xRouter
+//	chain-lens allowances -discover -from 18000000
+func runAllowances(args []string) {
+	fs := flag.NewFlagSet("allowances", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	filePath := fs.String("file", "wallets.txt", "包含钱包地址的文件路径 (每行一个)")
+	tokensFlag := fs.String("tokens", "", "逗号分隔的代币地址 (默认取配置 token_address)")
+	spendersFlag := fs.String("spenders", "", "逗号分隔的 spender 地址")
+	spendersFile := fs.String("spenders-file", "", "包含 spender 地址的文件路径 (每行一个)")
+	discover := fs.Bool("discover", false, "扫描 Approval 事件自动发现 spender")
+	fromBlock := fs.Uint64("from", 0, "-discover 扫描的起始区块")
+	chunk := fs.Uint64("chunk", core.DefaultLogChunk, "扫描日志的初始分段大小")
+	showAll := fs.Bool("all", false, "同时列出额度为 0 的授权")
+	outPath := fs.String("out", "", "把结果写成 JSON 文件")
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	cfg := loadConfig(*configPath)
+	tokens, err := parseAddressList(*tokensFlag)
+	if err != nil {
+		fatal(err)
+	}
+	if len(tokens) == 0 {
+		tokens = []common.Address{common.HexToAddress(cfg.TokenAddress)}
+	}
+	spenders, err := parseAddressList(*spendersFlag)
+	if err != nil {
+		fatal(err)
+	}
+	if *spendersFile != "" {
+		fromFile, err := loadAddresses(*spendersFile)
+		if err != nil {
+			fatal(i18n.Errorf("err.read_file", err))
+		}
+		spenders = append(spenders, fromFile...)
+	}
+	owners, err := loadAddresses(*filePath)
+	if err != nil {
+		fatal(i18n.Errorf("err.read_file", err))
+	}
+	logger.Info("wallet addresses loaded", "count", len(owners))
+
+	client, err := connect(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	defer client.Close()
+
+	startTime := time.Now()
+	blockNumber, err := client.Client.BlockNumber(context.Background())
+	if err != nil {
+		fatal(i18n.Errorf("err.block_number", err))
+	}
+
+	if *discover {
+		for _, token := range tokens {
+			logger.Info("scanning Approval logs", "token", token, "from_block", *fromBlock)
+			found, err := erc20.DiscoverSpenders(client, token, owners, *fromBlock, blockNumber, *chunk)
+			if err != nil {
+				fatal(i18n.Errorf("err.scan_approvals", err))
+			}
+			spenders = append(spenders, found...)
+		}
+	}
+	spenders = dedupAddresses(spenders)
+	if len(spenders) == 0 {
+		fatal(i18n.Errorf("err.no_spenders"))
+	}
+	logger.Info("checking allowances", "tokens", len(tokens), "wallets", len(owners), "spenders", len(spenders))
+
+	mc, err := newMultiChecker(cfg, client, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		fatal(err)
+	}
+	allowances, err := erc20.Allowances(mc, tokens, owners, spenders, core.DefaultBatchSize)
+	if err != nil {
+		fatal(i18n.Errorf("err.allowances", err))
+	}
+	// 配置了报价路由时按美元价值排序，否则不同代币的额度不可比，按代币分组
+	if len(cfg.Prices) > 0 {
+		erc20.SetPrices(allowances, tokenPrices(cfg, client, new(big.Int).SetUint64(blockNumber), tokens))
+	}
+	erc20.SortByExposure(allowances)
+
+	var outstanding []erc20.Allowance
+	unlimited, failed := 0, 0
+	for _, a := range allowances {
+		if !a.Success {
+			failed++
+			continue
+		}
+		if a.Raw.Sign() == 0 && !*showAll {
+			continue
+		}
+		outstanding = append(outstanding, a)
+		amount := fmt.Sprintf("%.4f", a.Amount)
+		mark := "🔸"
+		if a.Unlimited {
+			unlimited++
+			amount = i18n.T("report.allowances.unlimited_amount")
+			mark = "🚨"
+		}
+		if a.ValueUSD != nil && !a.Unlimited {
+			amount += fmt.Sprintf(" ($%.2f)", a.ValueUSD)
+		}
+		fmt.Printf("%s %s → %s | %s %s\n", mark, a.Owner.Hex(), a.Spender.Hex(), amount, a.Symbol)
+	}
+
+	fmt.Printf("\n--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.allowances.title", blockNumber))
+	fmt.Printf("--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.allowances.checked", len(allowances)))
+	fmt.Println(i18n.T("report.allowances.outstanding", len(outstanding)-countZero(outstanding)))
+	fmt.Println(i18n.T("report.allowances.unlimited", unlimited))
+	if failed > 0 {
+		fmt.Println(i18n.T("report.allowances.failed", failed))
+	}
+	fmt.Println(i18n.T("report.allowances.time", time.Since(startTime)))
+	fmt.Printf("--------------------------------------------------\n")
+
+	if *outPath != "" {
+		rep := allowanceReport{ChainID: client.ChainID.Int64(), Block: blockNumber, Allowances: outstanding}
+		if err := report.WriteJSON(*outPath, rep); err != nil {
+			logger.Warn("failed to write report", "path", *outPath, "err", err)
+		} else {
+			logger.Info("report written", "path", *outPath)
+		}
+	}
+}
+
+// parseAddressList 解析逗号分隔的地址列表
+func parseAddressList(s string) ([]common.Address, error) {
+	var addrs []common.Address
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !common.IsHexAddress(part) {
+			return nil, i18n.Errorf("err.invalid_address", part)
+		}
+		addrs = append(addrs, common.HexToAddress(part))
+	}
+	return addrs, nil
+}
+
+func dedupAddresses(addrs []common.Address) []common.Address {
+	seen := make(map[common.Address]bool, len(addrs))
+	var out []common.Address
+	for _, a := range addrs {
+		if !seen[a] {
+			seen[a] = true
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func countZero(allowances []erc20.Allowance) int {
+	n := 0
+	for _, a := range allowances {
+		if a.Raw.Sign() == 0 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CanonicalMulticall3 绝大多数 EVM 链上 Multicall3 的部署地址
+var CanonicalMulticall3 = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// ChainInfo 链的基础信息，按 ChainID 查表，可被配置覆盖
+type ChainInfo struct {
+	ChainID         int64          `json:"chain_id"`
+	Name            string         `json:"name,omitempty"`
+	NativeSymbol    string         `json:"native_symbol,omitempty"`
+	NativeDecimals  uint8          `json:"native_decimals,omitempty"`
+	Multicall3      common.Address `json:"multicall3,omitempty"`
+	Multicall3Block uint64         `json:"multicall3_block,omitempty"` // Multicall3 部署区块，0 表示未知 (视为一直可用)
+}
+
+// knownChains 内置的链信息，未收录的链使用 ETH / 18 位精度 / 标准 Multicall3 地址
+var knownChains = map[int64]ChainInfo{
+	1:        {Name: "Ethereum", NativeSymbol: "ETH", Multicall3Block: 14353601},
+	10:       {Name: "Optimism", NativeSymbol: "ETH", Multicall3Block: 4286263},
+	56:       {Name: "BNB Smart Chain", NativeSymbol: "BNB", Multicall3Block: 15921452},
+	100:      {Name: "Gnosis", NativeSymbol: "xDAI", Multicall3Block: 21022491},
+	137:      {Name: "Polygon", NativeSymbol: "POL", Multicall3Block: 25770160},
+	250:      {Name: "Fantom", NativeSymbol: "FTM", Multicall3Block: 33001987},
+	324:      {Name: "zkSync Era", NativeSymbol: "ETH", Multicall3: common.HexToAddress("0xF9cda624FBC7e059355ce98a31693d299FACd963")},
+	1868:     {Name: "Soneium", NativeSymbol: "ETH"},
+	5000:     {Name: "Mantle", NativeSymbol: "MNT"},
+	8453:     {Name: "Base", NativeSymbol: "ETH", Multicall3Block: 5022},
+	42161:    {Name: "Arbitrum One", NativeSymbol: "ETH", Multicall3Block: 7654707},
+	42220:    {Name: "Celo", NativeSymbol: "CELO"},
+	43114:    {Name: "Avalanche C-Chain", NativeSymbol: "AVAX", Multicall3Block: 11907934},
+	59144:    {Name: "Linea", NativeSymbol: "ETH"},
+	81457:    {Name: "Blast", NativeSymbol: "ETH"},
+	534352:   {Name: "Scroll", NativeSymbol: "ETH"},
+	11155111: {Name: "Sepolia", NativeSymbol: "ETH", Multicall3Block: 751532},
+}
+
+// LookupChain 按 ChainID 返回链信息：先查内置表，再用 overrides 里同 ChainID 的非空字段覆盖，
+// 最后给缺失字段补默认值。
+func LookupChain(chainID *big.Int, overrides []ChainInfo) ChainInfo {
+	var id int64
+	if chainID != nil {
+		id = chainID.Int64()
+	}
+	info := knownChains[id]
+	info.ChainID = id
+
+	for _, o := range overrides {
+		if o.ChainID != id {
+			continue
+		}
+		if o.Name != "" {
+			info.Name = o.Name
+		}
+		if o.NativeSymbol != "" {
+			info.NativeSymbol = o.NativeSymbol
+		}
+		if o.NativeDecimals != 0 {
+			info.NativeDecimals = o.NativeDecimals
+		}
+		if o.Multicall3 != (common.Address{}) {
+			info.Multicall3 = o.Multicall3
+		}
+		if o.Multicall3Block != 0 {
+			info.Multicall3Block = o.Multicall3Block
+		}
+	}
+
+	if info.Name == "" {
+		info.Name = "chain " + big.NewInt(id).String()
+	}
+	if info.NativeSymbol == "" {
+		info.NativeSymbol = "ETH"
+	}
+	if info.NativeDecimals == 0 {
+		info.NativeDecimals = 18
+	}
+	if info.Multicall3 == (common.Address{}) {
+		info.Multicall3 = CanonicalMulticall3
+	}
+	return info
+}
+
+// MulticallAvailableAt 判断 Multicall3 在指定区块是否已部署 (block 为 nil 表示 latest)
+func (c ChainInfo) MulticallAvailableAt(block *big.Int) bool {
+	if block == nil || c.Multicall3Block == 0 {
+		return true
+	}
+	return block.Uint64() >= c.Multicall3Block
+}
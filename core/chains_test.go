@@ -0,0 +1,27 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLookupChain(t *testing.T) {
+	bsc := LookupChain(big.NewInt(56), nil)
+	if bsc.NativeSymbol != "BNB" || bsc.NativeDecimals != 18 || bsc.Multicall3 != CanonicalMulticall3 {
+		t.Fatalf("unexpected bsc info: %+v", bsc)
+	}
+
+	custom := common.HexToAddress("0x0000000000000000000000000000000000001234")
+	got := LookupChain(big.NewInt(999999), []ChainInfo{
+		{ChainID: 1, NativeSymbol: "WRONG"},
+		{ChainID: 999999, NativeSymbol: "TST", Multicall3: custom, Multicall3Block: 100},
+	})
+	if got.NativeSymbol != "TST" || got.Multicall3 != custom || got.NativeDecimals != 18 {
+		t.Fatalf("override not applied: %+v", got)
+	}
+	if got.MulticallAvailableAt(big.NewInt(99)) || !got.MulticallAvailableAt(big.NewInt(100)) || !got.MulticallAvailableAt(nil) {
+		t.Fatal("unexpected multicall availability")
+	}
+}
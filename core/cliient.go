@@ -1,12 +1,15 @@
 package core
 
 import (
+	"chain-lens/metrics"
 	"context"
 	"fmt"
 	"math/big"
+	"net/http"
 	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 const (
@@ -19,9 +22,17 @@ type EvmClient struct {
 	RPC     string
 	Client  *ethclient.Client
 	ChainID *big.Int
+	Chain   ChainInfo // 按 ChainID 查到的链信息 (原生币符号、Multicall3 地址等)
+	Logger  Logger    // 诊断日志，nil 表示 DefaultLogger；基于这个连接创建的 checker 默认沿用它
 }
 
 func NewClient(rpcUrl string) (*EvmClient, error) {
+	return NewClientWithLogger(rpcUrl, nil)
+}
+
+// NewClientWithLogger 同 NewClient，连接重试等诊断信息写到 logger (nil 表示 DefaultLogger)
+func NewClientWithLogger(rpcUrl string, logger Logger) (*EvmClient, error) {
+	var client *ethclient.Client
 	var err error
 
 	for i := 0; i < MaxRetries; i++ {
@@ -29,7 +40,7 @@ func NewClient(rpcUrl string) (*EvmClient, error) {
 		ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
 
 		// 尝试连接
-		client, err := ethclient.DialContext(ctx, rpcUrl)
+		client, err = Dial(ctx, rpcUrl)
 		cancel()
 		if err == nil {
 			// 连接成功后，再查个 ChainID 确认节点真的活着
@@ -42,19 +53,37 @@ func NewClient(rpcUrl string) (*EvmClient, error) {
 				return &EvmClient{
 					Client:  client,
 					ChainID: chainID,
+					Chain:   LookupChain(chainID, nil),
 					RPC:     rpcUrl,
+					Logger:  logger,
 				}, nil
 			}
 			err = cidErr // 如果 ChainID 失败，更新错误信息
 		}
 
-		// 如果失败了，打印日志并等待
-		fmt.Printf("⚠️ 连接失败 (尝试 %d/%d): %v. %s后重试...\n", i+1, MaxRetries, err, RetryInterval)
+		// 如果失败了，记录日志并等待
+		LoggerOrDefault(logger).Warn("rpc connect failed, retrying",
+			"rpc", metrics.EndpointLabel(rpcUrl), "attempt", i+1, "max", MaxRetries, "retry_in", RetryInterval, "err", err)
 		time.Sleep(RetryInterval)
 	}
 
 	// 都失败，彻底放弃
-	return nil, fmt.Errorf("❌ 重试 %d 次后连接失败: %w", MaxRetries, err)
+	return nil, fmt.Errorf("connect %s failed after %d attempts: %w", metrics.EndpointLabel(rpcUrl), MaxRetries, err)
+}
+
+// Dial 连接 RPC 节点。HTTP 连接经过 metrics.Transport，按端点和方法记录请求耗时。
+func Dial(ctx context.Context, rpcUrl string) (*ethclient.Client, error) {
+	httpClient := &http.Client{Transport: metrics.NewTransport(rpcUrl, http.DefaultTransport)}
+	rc, err := rpc.DialOptions(ctx, rpcUrl, rpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rc), nil
+}
+
+// Log 返回这个连接的日志器
+func (c *EvmClient) Log() Logger {
+	return LoggerOrDefault(c.Logger)
 }
 
 func (c *EvmClient) Close() {
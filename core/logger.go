@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Logger 诊断日志接口 (连接重试、批量方式选择、补救查询等)，查询结果不走日志。
+// 方法签名和 *slog.Logger 一致，可以直接传入 slog.Logger；args 为交替的 key / value。
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+var defaultLogger atomic.Value // Logger
+
+func init() {
+	defaultLogger.Store(loggerBox{slog.New(slog.NewTextHandler(os.Stderr, nil))})
+}
+
+// loggerBox atomic.Value 要求每次存入的具体类型相同
+type loggerBox struct{ Logger }
+
+// DefaultLogger 没有单独注入日志器的组件使用的日志器，默认以文本格式写到 stderr
+func DefaultLogger() Logger {
+	return defaultLogger.Load().(loggerBox).Logger
+}
+
+// SetDefaultLogger 替换默认日志器，传入 nil 时丢弃所有日志
+func SetDefaultLogger(l Logger) {
+	if l == nil {
+		l = NopLogger()
+	}
+	defaultLogger.Store(loggerBox{l})
+}
+
+// NopLogger 丢弃所有日志
+func NopLogger() Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// NewLogger 创建写到 w 的日志器，format 为 text 或 json
+func NewLogger(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format: %s", format)
+	}
+}
+
+// ParseLevel 解析日志级别：debug / info / warn / error
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level: %s", s)
+	}
+	return level, nil
+}
+
+// LoggerOrDefault 组件没有注入日志器 (nil) 时退回默认日志器
+func LoggerOrDefault(l Logger) Logger {
+	if l == nil {
+		return DefaultLogger()
+	}
+	return l
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewLogger(&buf, "json", slog.LevelWarn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("dropped")
+	l.Warn("rpc connect failed, retrying", "attempt", 2)
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("expected exactly one json record, got %q: %v", buf.String(), err)
+	}
+	if rec["msg"] != "rpc connect failed, retrying" || rec["level"] != "WARN" || rec["attempt"] != float64(2) {
+		t.Fatalf("unexpected record: %v", rec)
+	}
+
+	if _, err := NewLogger(&buf, "xml", slog.LevelInfo); err == nil {
+		t.Fatal("unknown format must be rejected")
+	}
+	if level, err := ParseLevel("debug"); err != nil || level != slog.LevelDebug {
+		t.Fatalf("ParseLevel(debug) = %v, %v", level, err)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatal("unknown level must be rejected")
+	}
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLogChunk uint64 = 5000   // 默认每次 eth_getLogs 查询的区块数
+	MaxLogChunk     uint64 = 100000 // 自适应放大的上限
+)
+
+// 各家 RPC 对 eth_getLogs 区块范围/结果数量限制的报错关键字
+var logRangeErrors = []string{
+	"block range",
+	"range is too large",
+	"range too large",
+	"exceed maximum block range",
+	"more than 10000 results",
+	"query returned more than",
+	"too many results",
+	"response size exceeded",
+	"log response size",
+	"-32005",
+}
+
+// 限流报错的关键字。-32005 在部分节点上也用于限流，所以先排除限流，缩小范围只会让请求更多
+var rateLimitErrors = []string{
+	"rate limit",
+	"too many requests",
+	"request rate",
+	"exceeded its compute units",
+}
+
+// IsLogRangeError 判断错误是否是节点的日志范围/结果数限制 (限流不算)
+func IsLogRangeError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, key := range rateLimitErrors {
+		if strings.Contains(msg, key) {
+			return false
+		}
+	}
+	for _, key := range logRangeErrors {
+		if strings.Contains(msg, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanBlockRange 把 [from, to] 切成若干段依次调用 fetch，段大小自适应：
+// 遇到范围限制就减半重试，连续成功后逐步放大，其他错误按 MaxRetries 重试；
+// fetch 返回 context 取消或超时时立即停止。
+func ScanBlockRange(from, to, chunk uint64, fetch func(start, end uint64) error) error {
+	if chunk == 0 {
+		chunk = DefaultLogChunk
+	}
+	start := from
+	retries := 0
+	for start <= to {
+		end := start + chunk - 1
+		if end > to || end < start { // end < start: 溢出保护
+			end = to
+		}
+
+		err := fetch(start, end)
+		switch {
+		case err == nil:
+			retries = 0
+			if end == to {
+				return nil
+			}
+			start = end + 1
+			// 成功后放大段长度，减少请求次数
+			if chunk < MaxLogChunk {
+				chunk = min(chunk*2, MaxLogChunk)
+			}
+		case IsLogRangeError(err) && end > start:
+			// 范围太大：缩小一半，同一起点重试
+			chunk = max((end-start+1)/2, 1)
+		case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+			return fmt.Errorf("scan logs %d-%d: %w", start, end, err)
+		default:
+			retries++
+			if retries >= MaxRetries {
+				return fmt.Errorf("scan logs %d-%d: %w", start, end, err)
+			}
+			time.Sleep(RetryInterval)
+		}
+	}
+	return nil
+}
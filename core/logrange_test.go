@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScanBlockRangeAdaptive(t *testing.T) {
+	const limit = 1000
+	var ranges [][2]uint64
+	fetch := func(start, end uint64) error {
+		if end-start+1 > limit {
+			return errors.New("query exceeds max block range 1000")
+		}
+		ranges = append(ranges, [2]uint64{start, end})
+		return nil
+	}
+
+	if err := ScanBlockRange(100, 20000, 5000, fetch); err != nil {
+		t.Fatal(err)
+	}
+
+	// 覆盖整个区间且不重叠、不留空
+	next := uint64(100)
+	for _, r := range ranges {
+		if r[0] != next {
+			t.Fatalf("gap or overlap at %d, range %v", next, r)
+		}
+		next = r[1] + 1
+	}
+	if next != 20001 {
+		t.Fatalf("scan stopped at %d", next-1)
+	}
+}
+
+func TestScanBlockRangeGivesUp(t *testing.T) {
+	calls := 0
+	err := ScanBlockRange(0, 10, 5, func(start, end uint64) error {
+		calls++
+		return errors.New("connection refused")
+	})
+	if err == nil || calls != MaxRetries {
+		t.Fatalf("expected failure after %d calls, got %d calls, err=%v", MaxRetries, calls, err)
+	}
+}
+
+func TestIsLogRangeError(t *testing.T) {
+	ranges := []string{
+		"query exceeds max block range 1000",
+		"query returned more than 10000 results",
+		"log response size exceeded",
+		"-32005: query timeout exceeded",
+	}
+	for _, msg := range ranges {
+		if !IsLogRangeError(errors.New(msg)) {
+			t.Errorf("%q should be a range error", msg)
+		}
+	}
+	// 限流不是范围问题，缩小范围只会发更多请求
+	limits := []string{
+		"429 Too Many Requests",
+		"daily request count exceeded, request rate limited",
+		"-32005: project ID request rate exceeded",
+		"Your app has exceeded its compute units per second capacity",
+	}
+	for _, msg := range limits {
+		if IsLogRangeError(errors.New(msg)) {
+			t.Errorf("%q should not be a range error", msg)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+)
+
+// ClientPool 同一条链的多个 RPC 连接，按轮询分配请求
+type ClientPool struct {
+	Clients []*EvmClient
+	ChainID *big.Int
+	next    atomic.Uint64
+}
+
+// NewClientPool 连接所有 RPC，连不上的跳过；全部失败或 ChainID 不一致时返回错误
+func NewClientPool(rpcURLs []string) (*ClientPool, error) {
+	if len(rpcURLs) == 0 {
+		return nil, errors.New("no rpc url configured")
+	}
+	pool := &ClientPool{}
+	var lastErr error
+	for _, url := range rpcURLs {
+		client, err := NewClient(url)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if pool.ChainID == nil {
+			pool.ChainID = client.ChainID
+		} else if pool.ChainID.Cmp(client.ChainID) != 0 {
+			pool.Close()
+			client.Close()
+			return nil, fmt.Errorf("rpc %s is on chain %s, expected %s", url, client.ChainID, pool.ChainID)
+		}
+		pool.Clients = append(pool.Clients, client)
+	}
+	if len(pool.Clients) == 0 {
+		return nil, fmt.Errorf("all %d rpc urls failed: %w", len(rpcURLs), lastErr)
+	}
+	return pool, nil
+}
+
+// NewPoolOf 用已经建立好的连接组成连接池，不会重新连接；clients 必须属于同一条链
+func NewPoolOf(clients ...*EvmClient) *ClientPool {
+	pool := &ClientPool{Clients: clients}
+	if len(clients) > 0 {
+		pool.ChainID = clients[0].ChainID
+	}
+	return pool
+}
+
+// Next 轮询返回下一个连接
+func (p *ClientPool) Next() *EvmClient {
+	i := p.next.Add(1) - 1
+	return p.Clients[i%uint64(len(p.Clients))]
+}
+
+// SetChain 给池里所有连接设置链信息 (用于应用配置覆盖)
+func (p *ClientPool) SetChain(info ChainInfo) {
+	for _, c := range p.Clients {
+		c.Chain = info
+	}
+}
+
+func (p *ClientPool) Close() {
+	for _, c := range p.Clients {
+		c.Close()
+	}
+}
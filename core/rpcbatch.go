@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	DefaultRPCBatchSize = 100              // 单个 JSON-RPC 批量请求里的最大请求数 (多数节点服务商限制在 100~1000)
+	RPCBatchTimeout     = 10 * time.Second // 单个批量请求的超时时间
+)
+
+// RPCBatcher 通过 JSON-RPC 批量请求 ([{...},{...}]) 一次发送多个请求，不依赖任何合约
+type RPCBatcher struct {
+	RPC         *rpc.Client
+	BlockNumber *big.Int // 固定查询的区块高度，nil 表示 latest
+	BatchSize   int
+	Sequential  bool            // 节点不支持批量时逐个发送
+	Context     context.Context // 取消或超时后停止发送，nil 表示 context.Background()
+}
+
+func NewRPCBatcher(client *rpc.Client) *RPCBatcher {
+	return &RPCBatcher{RPC: client, BatchSize: DefaultRPCBatchSize}
+}
+
+// Batcher 返回基于当前连接的批量请求器
+func (c *EvmClient) Batcher(block *big.Int) *RPCBatcher {
+	b := NewRPCBatcher(c.Client.Client())
+	b.BlockNumber = block
+	return b
+}
+
+// BatchCall 发送原始 JSON-RPC 批量请求，超过 BatchSize 时自动分批。
+// 返回的 error 只表示传输失败，单个请求的错误记录在各自的 BatchElem.Error 里。
+func (c *EvmClient) BatchCall(elems []rpc.BatchElem) error {
+	return NewRPCBatcher(c.Client.Client()).BatchCall(elems)
+}
+
+func (b *RPCBatcher) BatchCall(elems []rpc.BatchElem) error {
+	size := b.BatchSize
+	if size <= 0 {
+		size = DefaultRPCBatchSize
+	}
+	if b.Sequential {
+		for i := range elems {
+			ctx, cancel := context.WithTimeout(b.ctx(), RequestTimeout)
+			elems[i].Error = b.RPC.CallContext(ctx, elems[i].Result, elems[i].Method, elems[i].Args...)
+			cancel()
+		}
+		return nil
+	}
+	for start := 0; start < len(elems); start += size {
+		end := min(start+size, len(elems))
+		ctx, cancel := context.WithTimeout(b.ctx(), RPCBatchTimeout)
+		err := b.RPC.BatchCallContext(ctx, elems[start:end])
+		cancel()
+		if err != nil {
+			return fmt.Errorf("rpc batch failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// Aggregate 实现 BatchCaller：每个调用对应一个 eth_call，Data 为空时改为 eth_getBalance
+func (b *RPCBatcher) Aggregate(calls []Call) ([]CallResult, error) {
+	elems := make([]rpc.BatchElem, len(calls))
+	outs := make([]hexutil.Bytes, len(calls))
+	balances := make([]hexutil.Big, len(calls))
+	for i, c := range calls {
+		if len(c.Data) == 0 {
+			elems[i] = rpc.BatchElem{Method: "eth_getBalance", Args: []any{c.Target, b.blockArg()}, Result: &balances[i]}
+			continue
+		}
+		msg := map[string]any{"to": c.Target, "data": hexutil.Bytes(c.Data)}
+		elems[i] = rpc.BatchElem{Method: "eth_call", Args: []any{msg, b.blockArg()}, Result: &outs[i]}
+	}
+	if err := b.BatchCall(elems); err != nil {
+		return nil, err
+	}
+	results := make([]CallResult, len(calls))
+	for i, c := range calls {
+		if elems[i].Error != nil {
+			continue
+		}
+		results[i].Success = true
+		if len(c.Data) == 0 {
+			results[i].ReturnData = common.LeftPadBytes(balances[i].ToInt().Bytes(), 32)
+		} else {
+			results[i].ReturnData = outs[i]
+		}
+	}
+	return results, nil
+}
+
+// Balances 批量查询原生币余额 (wei)，失败的地址对应 nil
+func (b *RPCBatcher) Balances(addrs []common.Address) ([]*big.Int, error) {
+	results := make([]hexutil.Big, len(addrs))
+	elems := b.perAddress("eth_getBalance", addrs, func(i int) any { return &results[i] })
+	if err := b.BatchCall(elems); err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(addrs))
+	for i := range elems {
+		if elems[i].Error == nil {
+			balances[i] = results[i].ToInt()
+		}
+	}
+	return balances, nil
+}
+
+// Codes 批量查询地址上的合约代码，失败的地址对应 nil，没有代码的地址对应空切片
+func (b *RPCBatcher) Codes(addrs []common.Address) ([][]byte, error) {
+	results := make([]hexutil.Bytes, len(addrs))
+	elems := b.perAddress("eth_getCode", addrs, func(i int) any { return &results[i] })
+	if err := b.BatchCall(elems); err != nil {
+		return nil, err
+	}
+	codes := make([][]byte, len(addrs))
+	for i := range elems {
+		if elems[i].Error == nil {
+			codes[i] = append([]byte{}, results[i]...)
+		}
+	}
+	return codes, nil
+}
+
+// Nonces 批量查询地址已发送的交易数，失败的地址对应 -1
+func (b *RPCBatcher) Nonces(addrs []common.Address) ([]int64, error) {
+	results := make([]hexutil.Uint64, len(addrs))
+	elems := b.perAddress("eth_getTransactionCount", addrs, func(i int) any { return &results[i] })
+	if err := b.BatchCall(elems); err != nil {
+		return nil, err
+	}
+	nonces := make([]int64, len(addrs))
+	for i := range elems {
+		nonces[i] = -1
+		if elems[i].Error == nil {
+			nonces[i] = int64(results[i])
+		}
+	}
+	return nonces, nil
+}
+
+func (b *RPCBatcher) perAddress(method string, addrs []common.Address, result func(i int) any) []rpc.BatchElem {
+	elems := make([]rpc.BatchElem, len(addrs))
+	for i, addr := range addrs {
+		elems[i] = rpc.BatchElem{Method: method, Args: []any{addr, b.blockArg()}, Result: result(i)}
+	}
+	return elems
+}
+
+func (b *RPCBatcher) ctx() context.Context {
+	if b.Context == nil {
+		return context.Background()
+	}
+	return b.Context
+}
+
+func (b *RPCBatcher) blockArg() string {
+	if b.BlockNumber == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(b.BlockNumber)
+}
+
+// SupportsRPCBatch 发送一个只包含 eth_chainId 的批量请求，判断节点是否支持 JSON-RPC 批量
+func SupportsRPCBatch(client *rpc.Client) bool {
+	var a, b hexutil.Big
+	elems := []rpc.BatchElem{
+		{Method: "eth_chainId", Result: &a},
+		{Method: "eth_chainId", Result: &b},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
+	defer cancel()
+	if err := client.BatchCallContext(ctx, elems); err != nil {
+		return false
+	}
+	return elems[0].Error == nil && elems[1].Error == nil
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeEth 最小的 eth 命名空间，只实现批量请求会用到的方法
+type fakeEth struct{}
+
+type callArgs struct {
+	To   common.Address `json:"to"`
+	Data hexutil.Bytes  `json:"data"`
+}
+
+func (fakeEth) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+func (fakeEth) GetBalance(addr common.Address, block string) *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int).SetBytes(addr[18:]))
+}
+
+func (fakeEth) GetTransactionCount(addr common.Address, block string) hexutil.Uint64 {
+	return hexutil.Uint64(addr[19])
+}
+
+func (fakeEth) GetCode(addr common.Address, block string) hexutil.Bytes {
+	if addr[19]%2 == 0 {
+		return nil
+	}
+	return hexutil.Bytes{0x60, 0x00}
+}
+
+func (fakeEth) Call(args callArgs, block string) (hexutil.Bytes, error) {
+	if len(args.Data) > 0 && args.Data[0] == 0xff {
+		return nil, errors.New("execution reverted")
+	}
+	return append(hexutil.Bytes{}, args.Data...), nil
+}
+
+func TestRPCBatcher(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", fakeEth{}); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	if !SupportsRPCBatch(client) {
+		t.Fatal("expected batch support")
+	}
+	b := NewRPCBatcher(client)
+	b.BatchSize = 2
+	b.BlockNumber = big.NewInt(100)
+
+	addrs := []common.Address{
+		common.HexToAddress("0x0000000000000000000000000000000000000101"),
+		common.HexToAddress("0x0000000000000000000000000000000000000002"),
+		common.HexToAddress("0x0000000000000000000000000000000000000003"),
+	}
+	nonces, err := b.Nonces(addrs)
+	if err != nil || nonces[0] != 1 || nonces[2] != 3 {
+		t.Fatalf("nonces: %v %v", nonces, err)
+	}
+	codes, err := b.Codes(addrs)
+	if err != nil || len(codes[0]) == 0 || len(codes[1]) != 0 {
+		t.Fatalf("codes: %v %v", codes, err)
+	}
+
+	results, err := b.Aggregate([]Call{
+		{Target: addrs[0], Data: []byte{0x01, 0x02}},
+		{Target: addrs[1], Data: []byte{0xff}},
+		{Target: addrs[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Success || string(results[0].ReturnData) != "\x01\x02" {
+		t.Fatalf("call: %+v", results[0])
+	}
+	if results[1].Success {
+		t.Fatalf("reverted call should fail: %+v", results[1])
+	}
+	if !results[2].Success || new(big.Int).SetBytes(results[2].ReturnData).Int64() != 0x101 {
+		t.Fatalf("balance: %+v", results[2])
+	}
+}
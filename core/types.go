@@ -19,3 +19,63 @@ type TokenBalance struct {
 type AssetChecker interface {
 	BalanceOf(address common.Address) (*TokenBalance, error)
 }
+
+// Call 一次只读合约调用
+type Call struct {
+	Target common.Address // 被调用的合约地址
+	Data   []byte         // ABI 编码后的 calldata
+}
+
+// CallResult 单个调用的结果，失败 (revert) 时 Success 为 false
+type CallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// BatchCaller 把多个只读调用合并成一次请求执行 (例如 Multicall3)
+type BatchCaller interface {
+	Aggregate(calls []Call) ([]CallResult, error)
+}
+
+// DefaultBatchSize 每次批量调用默认打包的调用数
+const DefaultBatchSize = 500
+
+// AggregateInBatches 把大量调用切成多个批次交给 caller 执行，结果顺序与 calls 一致
+func AggregateInBatches(caller BatchCaller, calls []Call, batchSize int) ([]CallResult, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	results := make([]CallResult, 0, len(calls))
+	for i := 0; i < len(calls); i += batchSize {
+		res, err := caller.Aggregate(calls[i:min(i+batchSize, len(calls))])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
+
+// AccountKind 地址类型
+type AccountKind string
+
+const (
+	AccountEOA       AccountKind = "eoa"
+	AccountContract  AccountKind = "contract"
+	AccountDelegated AccountKind = "eip7702" // 通过 EIP-7702 委托给合约代码的 EOA
+)
+
+// AccountProfile 地址画像：账户类型、是否发过交易、原生币余额以及识别出的合约钱包
+type AccountProfile struct {
+	Address     common.Address   `json:"address"`
+	Kind        AccountKind      `json:"kind"`
+	Nonce       uint64           `json:"nonce"`
+	HasSentTx   bool             `json:"has_sent_tx"` // 仅对 EOA / 7702 账户有意义，合约的 nonce 是创建合约的次数
+	Balance     *big.Float       `json:"balance,omitempty"`
+	CodeSize    int              `json:"code_size"`
+	DelegatedTo *common.Address  `json:"delegated_to,omitempty"`
+	Wallet      string           `json:"wallet,omitempty"` // 识别出的智能合约钱包类型，例如 "safe"
+	Owners      []common.Address `json:"owners,omitempty"`
+	Threshold   uint64           `json:"threshold,omitempty"`
+	Success     bool             `json:"success"`
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/report"
+	"chain-lens/store"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// runDiff diff 子命令：对比两个快照的余额。
+// 快照来源可以是链上指定区块、结果库里的某次运行，或者 -out 写出的 JSON 文件：
+//
+//	chain-lens diff -from block:19000000 -to latest
+//	chain-lens diff -db results.db -from run:3 -to run:5
+//	chain-lens diff -from before.json -to after.json
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径 (按区块对比时需要)")
+	filePath := fs.String("file", "wallets.txt", "包含钱包地址的文件路径 (按区块对比时需要)")
+	dbPath := fs.String("db", "results.db", "SQLite 结果库路径 (使用 run:<ID> 时需要)")
+	from := fs.String("from", "", "旧快照: block:<高度> | latest | run:<运行ID> | <JSON 文件>")
+	to := fs.String("to", "latest", "新快照: block:<高度> | latest | run:<运行ID> | <JSON 文件>")
+	limit := fs.Int("limit", 50, "最多打印多少条钱包变化 (0 表示全部)")
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	if *from == "" {
+		fatal(i18n.Errorf("err.diff_from_required"))
+	}
+
+	loader := &snapshotLoader{configPath: *configPath, filePath: *filePath, dbPath: *dbPath}
+	defer loader.close()
+
+	fromSnap, err := loader.load(*from)
+	if err != nil {
+		fatal(i18n.Errorf("err.diff_load_from", err))
+	}
+	toSnap, err := loader.load(*to)
+	if err != nil {
+		fatal(i18n.Errorf("err.diff_load_to", err))
+	}
+	if fromSnap.TokenAddress != toSnap.TokenAddress {
+		logger.Warn("snapshots are for different tokens", "from", fromSnap.TokenAddress, "to", toSnap.TokenAddress)
+	}
+
+	printDiff(report.Compare(fromSnap, toSnap), *limit)
+}
+
+// snapshotLoader 按来源加载快照，需要时才连接 RPC / 打开结果库
+type snapshotLoader struct {
+	configPath, filePath, dbPath string
+
+	cfg       Config
+	client    *core.EvmClient
+	addresses []common.Address
+	db        *store.Store
+}
+
+func (l *snapshotLoader) load(spec string) (*report.Snapshot, error) {
+	switch {
+	case spec == "latest":
+		return l.fromChain(nil)
+	case strings.HasPrefix(spec, "block:"):
+		n, err := strconv.ParseUint(strings.TrimPrefix(spec, "block:"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block %q: %w", spec, err)
+		}
+		return l.fromChain(new(big.Int).SetUint64(n))
+	case strings.HasPrefix(spec, "run:"):
+		id, err := strconv.ParseInt(strings.TrimPrefix(spec, "run:"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run id %q: %w", spec, err)
+		}
+		return l.fromRun(id)
+	default:
+		return report.ReadJSON(spec)
+	}
+}
+
+// fromChain 在指定区块上重新查询一遍余额 (block 为 nil 时固定到当前最新区块)
+func (l *snapshotLoader) fromChain(block *big.Int) (*report.Snapshot, error) {
+	if l.client == nil {
+		l.cfg = loadConfig(l.configPath)
+		addresses, err := loadAddresses(l.filePath)
+		if err != nil {
+			return nil, err
+		}
+		l.addresses = addresses
+		client, err := connect(l.cfg)
+		if err != nil {
+			return nil, err
+		}
+		l.client = client
+	}
+	if block == nil {
+		head, err := l.client.Client.BlockNumber(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("get block number: %w", err)
+		}
+		block = new(big.Int).SetUint64(head)
+	}
+	logger.Info("fetching balances", "wallets", len(l.addresses), "block", block)
+	balances := collectBalances(l.cfg, l.client, l.addresses, block)
+	return report.NewSnapshot(l.client.ChainID.Int64(), block.Uint64(), common.HexToAddress(l.cfg.TokenAddress), balances), nil
+}
+
+func (l *snapshotLoader) fromRun(id int64) (*report.Snapshot, error) {
+	if l.db == nil {
+		db, err := store.Open(l.dbPath)
+		if err != nil {
+			return nil, err
+		}
+		l.db = db
+	}
+	run, balances, err := l.db.LoadRun(id)
+	if err != nil {
+		return nil, err
+	}
+	snap := report.NewSnapshot(run.ChainID, run.Block, run.TokenAddress, balances)
+	snap.Timestamp = run.StartedAt
+	return snap, nil
+}
+
+func (l *snapshotLoader) close() {
+	if l.client != nil {
+		l.client.Close()
+	}
+	if l.db != nil {
+		l.db.Close()
+	}
+}
+
+func printDiff(d *report.Diff, limit int) {
+	symbol := d.To.Symbol
+	if symbol == "" {
+		symbol = d.From.Symbol
+	}
+	fmt.Printf("\n--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.diff.title", d.From.Block, d.To.Block))
+	fmt.Printf("--------------------------------------------------\n")
+	for i, c := range d.Changes {
+		if limit > 0 && i >= limit {
+			fmt.Println(i18n.T("report.diff.more", len(d.Changes)-limit))
+			break
+		}
+		mark := "🔺"
+		if c.Delta.Sign() < 0 {
+			mark = "🔻"
+		}
+		fmt.Printf("%s %s | %.4f → %.4f | %+.4f %s\n", mark, c.Owner.Hex(), c.From, c.To, c.Delta, symbol)
+	}
+	fmt.Printf("--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.diff.changed", len(d.Changes)))
+	fmt.Println(i18n.T("report.diff.new", len(d.NewHolders)))
+	for _, addr := range d.NewHolders {
+		fmt.Printf("   + %s\n", addr.Hex())
+	}
+	fmt.Println(i18n.T("report.diff.exited", len(d.ExitedHolders)))
+	for _, addr := range d.ExitedHolders {
+		fmt.Printf("   - %s\n", addr.Hex())
+	}
+	if len(d.Skipped) > 0 {
+		fmt.Println(i18n.T("report.diff.skipped", len(d.Skipped)))
+	}
+	fmt.Println(i18n.T("report.diff.total", d.TotalFrom, d.TotalTo, d.TotalDelta, symbol))
+	fmt.Printf("--------------------------------------------------\n")
+}
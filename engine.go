@@ -0,0 +1,191 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/multicall"
+	"chain-lens/report"
+	"chain-lens/scanner"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// engine serve / grpc 服务模式共用的查询引擎：一个 RPC 连接池上的 scanner.Scanner，
+// 批量方式在第一次查询时探测一次，之后每个请求只是换成自己的资产并固定到自己的区块上。
+// 单个请求的钱包数、区块范围、并发数和超时也在这里统一限制，两种服务的行为一致。
+type engine struct {
+	cfg     Config
+	pool    *core.ClientPool
+	scanner *scanner.Scanner
+	limits  limits
+	sem     chan struct{} // 限制同时执行的查询数
+}
+
+// limits 服务模式下单个请求的资源上限
+type limits struct {
+	timeout       time.Duration // 单个请求的超时时间
+	concurrency   int           // 同时执行的查询数上限
+	maxWallets    int           // 单个请求最多查询的钱包数 (包括扫描日志发现的持有人)
+	maxBlockRange uint64        // 扫描日志时单个请求最多覆盖的区块数
+}
+
+// addLimitFlags 注册 serve / grpc 共用的资源限制参数
+func addLimitFlags(fs *flag.FlagSet) *limits {
+	l := &limits{}
+	fs.DurationVar(&l.timeout, "timeout", 30*time.Second, "单个请求的超时时间")
+	fs.IntVar(&l.concurrency, "concurrency", 8, "同时执行的查询数上限")
+	fs.IntVar(&l.maxWallets, "max-wallets", 10000, "单个请求最多查询的钱包数")
+	fs.Uint64Var(&l.maxBlockRange, "max-block-range", 1_000_000, "扫描持有人时单个请求最多覆盖的区块数")
+	return l
+}
+
+func newEngine(cfg Config, pool *core.ClientPool, l limits) (*engine, error) {
+	e := &engine{cfg: cfg, pool: pool, limits: l, sem: make(chan struct{}, max(l.concurrency, 1))}
+	if pool == nil {
+		return e, nil
+	}
+	strategy, err := multicall.ParseStrategy(cfg.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	// 资产由每个请求指定，这里不校验配置里的代币
+	e.scanner, err = scanner.New(scanner.Options{Pool: pool, Strategy: strategy})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// connectPool 按配置建立 RPC 连接池并应用链信息覆盖，失败直接退出
+func connectPool(cfg Config) *core.ClientPool {
+	pool, err := core.NewClientPool(rpcURLs(cfg))
+	if err != nil {
+		fatal(err)
+	}
+	pool.SetChain(core.LookupChain(pool.ChainID, cfg.Chains))
+	return pool
+}
+
+// rpcURLs 配置了 rpc_urls 时使用连接池，否则只用 rpc_url
+func rpcURLs(cfg Config) []string {
+	if len(cfg.RpcURLs) > 0 {
+		return cfg.RpcURLs
+	}
+	return []string{cfg.RpcURL}
+}
+
+// asset 校验请求里的代币，两者都为空时使用配置文件里的代币
+func (e *engine) asset(tokenAddress, tokenType string) (Config, multicall.TokenType, error) {
+	cfg := e.cfg
+	if tokenType != "" || tokenAddress != "" {
+		cfg.TokenType = tokenType
+		cfg.TokenAddress = tokenAddress
+	}
+	tType, err := ParseTokenType(cfg.TokenType)
+	if err != nil {
+		return cfg, 0, err
+	}
+	if tType != multicall.TokenTypeNative && !common.IsHexAddress(cfg.TokenAddress) {
+		return cfg, 0, fmt.Errorf("invalid token_address: %q", cfg.TokenAddress)
+	}
+	return cfg, tType, nil
+}
+
+// wallets 检查钱包数量上限并解析地址
+func (e *engine) wallets(wallets []string) ([]common.Address, error) {
+	if err := e.checkWallets(len(wallets)); err != nil {
+		return nil, err
+	}
+	return parseWallets(wallets)
+}
+
+// checkWallets 检查单个请求的钱包数量上限
+func (e *engine) checkWallets(n int) error {
+	if e.limits.maxWallets > 0 && n > e.limits.maxWallets {
+		return fmt.Errorf("too many wallets: %d > %d", n, e.limits.maxWallets)
+	}
+	return nil
+}
+
+// checkBlockRange 要求扫描日志的区间有界：[from, to] 不能超过 maxBlockRange 个区块
+func (e *engine) checkBlockRange(from, to uint64) error {
+	if from > to {
+		return fmt.Errorf("from_block %d is after to_block %d", from, to)
+	}
+	if e.limits.maxBlockRange > 0 && to-from >= e.limits.maxBlockRange {
+		return fmt.Errorf("block range %d-%d spans %d blocks, max %d (set from_block closer to to_block)", from, to, to-from+1, e.limits.maxBlockRange)
+	}
+	return nil
+}
+
+// withTimeout 给请求加上超时
+func (e *engine) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if e.limits.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, e.limits.timeout)
+}
+
+// errBusy 在超时前没有拿到并发令牌
+var errBusy = errors.New("too many concurrent requests")
+
+// acquire 排队拿并发令牌，ctx 结束前拿不到时返回 errBusy；拿到后必须调用 release 归还
+func (e *engine) acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case e.sem <- struct{}{}:
+		return func() { <-e.sem }, nil
+	case <-ctx.Done():
+		return nil, errBusy
+	}
+}
+
+// parseWallets 校验并解析钱包地址列表
+func parseWallets(wallets []string) ([]common.Address, error) {
+	if len(wallets) == 0 {
+		return nil, errors.New("wallets must not be empty")
+	}
+	addresses := make([]common.Address, 0, len(wallets))
+	for _, w := range wallets {
+		if !common.IsHexAddress(w) {
+			return nil, fmt.Errorf("invalid wallet address: %q", w)
+		}
+		addresses = append(addresses, common.HexToAddress(w))
+	}
+	return addresses, nil
+}
+
+// head 返回要固定的区块高度，blockNumber 为 0 时取最新区块；节点无响应时随请求的 ctx 一起超时
+func (e *engine) head(ctx context.Context, client *core.EvmClient, blockNumber uint64) (uint64, error) {
+	if blockNumber != 0 {
+		return blockNumber, nil
+	}
+	head, err := client.Client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get block number: %w", err)
+	}
+	return head, nil
+}
+
+// at 返回查询 cfg 里的代币、固定在 blockNumber 上的 Scanner
+func (e *engine) at(cfg Config, tokenType multicall.TokenType, blockNumber uint64) *scanner.Scanner {
+	asset := scanner.Asset{Type: tokenType, Address: common.HexToAddress(cfg.TokenAddress)}
+	return e.scanner.WithAssets(asset).AtBlock(new(big.Int).SetUint64(blockNumber))
+}
+
+// snapshot 固定区块后查询余额并转换成快照
+func (e *engine) snapshot(ctx context.Context, cfg Config, tokenType multicall.TokenType, addresses []common.Address, blockNumber uint64) (*report.Snapshot, error) {
+	blockNumber, err := e.head(ctx, e.pool.Next(), blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	results, err := e.at(cfg, tokenType, blockNumber).Scan(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	return report.NewSnapshot(e.pool.ChainID.Int64(), blockNumber, common.HexToAddress(cfg.TokenAddress), results[0].Balances), nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/progress"
+	"chain-lens/scanner"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// logger CLI 的诊断日志 (写到 stderr)；报告和查询结果仍然用 fmt 写到 stdout，方便重定向和管道处理
+var logger core.Logger = core.DefaultLogger()
+
+// progressMode 长时间扫描的进度输出方式 (写到 stderr)，由 -progress 设置
+var progressMode = progress.ModeAuto
+
+// cliOptions 所有子命令共用的日志和语言参数，默认值可以用环境变量设置
+type cliOptions struct {
+	level    string
+	format   string
+	quiet    bool
+	lang     string
+	progress string
+}
+
+func addCommonFlags(fs *flag.FlagSet) *cliOptions {
+	o := &cliOptions{}
+	fs.StringVar(&o.level, "log-level", envOr("CHAIN_LENS_LOG_LEVEL", "info"), "日志级别: debug / info / warn / error")
+	fs.StringVar(&o.format, "log-format", envOr("CHAIN_LENS_LOG_FORMAT", "text"), "日志格式: text / json")
+	fs.BoolVar(&o.quiet, "quiet", os.Getenv("CHAIN_LENS_QUIET") != "", "只输出错误日志 (结果照常输出到 stdout)")
+	fs.StringVar(&o.lang, "lang", "", "界面语言: en / zh (默认依次取 CHAIN_LENS_LANG、LC_ALL、LC_MESSAGES、LANG)")
+	fs.StringVar(&o.progress, "progress", envOr("CHAIN_LENS_PROGRESS", "auto"), "扫描进度: auto (终端显示进度条，否则定期打日志) / bar / log / off")
+	return o
+}
+
+// setup 按参数设置界面语言，创建日志器并设为 core 的默认日志器；标准库 log 的输出也转成 error 级别日志
+func (o *cliOptions) setup() {
+	if o.lang != "" {
+		if _, err := i18n.ParseLang(o.lang); err != nil {
+			fatal(err)
+		}
+	}
+	i18n.SetLang(i18n.Detect(o.lang))
+
+	level, err := core.ParseLevel(o.level)
+	if err != nil {
+		fatal(err)
+	}
+	mode, err := progress.ParseMode(o.progress)
+	if err != nil {
+		fatal(err)
+	}
+	if o.quiet {
+		level = slog.LevelError
+		mode = progress.ModeOff
+	}
+	progressMode = mode
+	l, err := core.NewLogger(os.Stderr, o.format, level)
+	if err != nil {
+		fatal(err)
+	}
+	logger = l
+	core.SetDefaultLogger(l)
+	log.SetFlags(0)
+	log.SetOutput(stdlogWriter{})
+}
+
+// withProgress 按 -progress 给扫描挂上进度输出；返回的 finish 要在输出结果之前调用，结束进度条那一行
+func withProgress(s *scanner.Scanner) (*scanner.Scanner, func()) {
+	if progressMode == progress.ModeOff {
+		return s, func() {}
+	}
+	r := progress.NewReporter(progressMode, os.Stderr, logger)
+	return s.WithProgress(r.Update), r.Finish
+}
+
+// fatal 输出错误并退出。本地化错误 (i18n.Error) 附带 code，code 不随语言变化，脚本应按它匹配。
+func fatal(err error) {
+	if code := i18n.Code(err); code != "" {
+		logger.Error(err.Error(), "code", code)
+	} else {
+		logger.Error(err.Error())
+	}
+	os.Exit(1)
+}
+
+// stdlogWriter 把标准库 log 的每一行作为 error 级别日志写出，quiet 模式下也不会被过滤
+type stdlogWriter struct{}
+
+func (stdlogWriter) Write(p []byte) (int, error) {
+	logger.Error(strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
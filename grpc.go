@@ -0,0 +1,223 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/grpcapi"
+	"chain-lens/modules/holders"
+	"chain-lens/modules/multicall"
+	"chain-lens/scanner"
+	"context"
+	"flag"
+	"math/big"
+	"net"
+
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcStreamWorkers 一个流式请求里同时查询的批次数
+const grpcStreamWorkers = 4
+
+// grpcServer 实现 grpcapi.BalanceServiceServer，和 serve 子命令共用查询引擎
+type grpcServer struct {
+	grpcapi.UnimplementedBalanceServiceServer
+	*engine
+}
+
+// runGRPC grpc 子命令：以 gRPC 服务的形式提供余额查询
+//
+//	chain-lens grpc -addr :9090
+func runGRPC(args []string) {
+	fs := flag.NewFlagSet("grpc", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	addr := fs.String("addr", ":9090", "监听地址")
+	lim := addLimitFlags(fs)
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	cfg := loadConfig(*configPath)
+	pool := connectPool(cfg)
+	defer pool.Close()
+	e, err := newEngine(cfg, pool, *lim)
+	if err != nil {
+		fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fatal(err)
+	}
+	server := grpc.NewServer()
+	grpcapi.RegisterBalanceServiceServer(server, &grpcServer{engine: e})
+	logger.Info("grpc serving", "chain", pool.Clients[0].Chain.Name, "chain_id", pool.ChainID, "rpc", len(pool.Clients), "addr", *addr)
+	fatal(server.Serve(lis))
+}
+
+func (s *grpcServer) BatchBalances(req *grpcapi.BatchBalancesRequest, stream grpc.ServerStreamingServer[grpcapi.BalanceBatch]) error {
+	cfg, tokenType, err := s.asset(req.GetAsset().GetTokenAddress(), req.GetAsset().GetTokenType())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	addresses, err := s.wallets(req.GetWallets())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	ctx, cancel := s.withTimeout(stream.Context())
+	defer cancel()
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	defer release()
+	blockNumber, err := s.head(ctx, s.pool.Next(), req.GetBlock())
+	if err != nil {
+		return headError(ctx, err)
+	}
+	return s.streamBalances(ctx, stream, cfg, tokenType, addresses, blockNumber, int(req.GetBatchSize()))
+}
+
+func (s *grpcServer) ScanHolders(req *grpcapi.ScanHoldersRequest, stream grpc.ServerStreamingServer[grpcapi.BalanceBatch]) error {
+	cfg, tokenType, err := s.asset(req.GetAsset().GetTokenAddress(), req.GetAsset().GetTokenType())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	client := s.pool.Next()
+	holderScanner, err := holders.NewScanner(client, tokenType, common.HexToAddress(cfg.TokenAddress))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.GetChunk() > 0 {
+		holderScanner.Chunk = req.GetChunk()
+	}
+	ctx, cancel := s.withTimeout(stream.Context())
+	defer cancel()
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	defer release()
+	to, err := s.head(ctx, client, req.GetToBlock())
+	if err != nil {
+		return headError(ctx, err)
+	}
+	if err := s.checkBlockRange(req.GetFromBlock(), to); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	addresses, err := holderScanner.CollectContext(ctx, req.GetFromBlock(), to)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	// 发现的持有人数量同样受 max-wallets 限制
+	if err := s.checkWallets(len(addresses)); err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return s.streamBalances(ctx, stream, cfg, tokenType, addresses, to, int(req.GetBatchSize()))
+}
+
+func (s *grpcServer) DetectToken(ctx context.Context, req *grpcapi.DetectTokenRequest) (*grpcapi.TokenInfo, error) {
+	if !common.IsHexAddress(req.GetTokenAddress()) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid token_address: %q", req.GetTokenAddress())
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	defer release()
+	client := s.pool.Next()
+	blockNumber, err := s.head(ctx, client, req.GetBlock())
+	if err != nil {
+		return nil, headError(ctx, err)
+	}
+	mc := s.scanner.AtBlock(new(big.Int).SetUint64(blockNumber)).Checker(client)
+	info, err := multicall.DetectToken(mc, common.HexToAddress(req.GetTokenAddress()))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	resp := &grpcapi.TokenInfo{
+		TokenAddress: info.Address.Hex(),
+		TokenType:    tokenTypeName(info.Type),
+		Name:         info.Name,
+		Symbol:       info.Symbol,
+		Decimals:     uint32(info.Decimals),
+	}
+	if info.TotalSupply != nil {
+		resp.TotalSupply = info.TotalSupply.String()
+	}
+	return resp, nil
+}
+
+// headError 查询区块高度失败：请求超时或取消时返回对应的状态码，否则视为节点不可用
+func headError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// streamBalances 按批并发查询，每完成一批就推送一次 (推送顺序即完成顺序)
+func (s *grpcServer) streamBalances(ctx context.Context, stream grpc.ServerStreamingServer[grpcapi.BalanceBatch], cfg Config, tokenType multicall.TokenType, addresses []common.Address, blockNumber uint64, batchSize int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := 0
+	var sendErr error
+	err := s.at(cfg, tokenType, blockNumber).WithBatching(batchSize, grpcStreamWorkers).Stream(ctx, addresses, func(b scanner.Batch) {
+		if sendErr != nil {
+			return
+		}
+		done += len(b.Balances)
+		batch := &grpcapi.BalanceBatch{
+			ChainId:  b.ChainID,
+			Block:    b.Block,
+			Balances: make([]*grpcapi.TokenBalance, 0, len(b.Balances)),
+			Done:     uint32(done),
+			Total:    uint32(len(addresses)),
+		}
+		for _, tb := range b.Balances {
+			batch.Balances = append(batch.Balances, toProtoBalance(tb))
+		}
+		// 发送失败 (客户端断开) 时取消剩下的批次
+		if sendErr = stream.Send(batch); sendErr != nil {
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// toProtoBalance 把 core.TokenBalance 转换成 protobuf 消息，余额用十进制字符串保留精度
+func toProtoBalance(tb core.TokenBalance) *grpcapi.TokenBalance {
+	pb := &grpcapi.TokenBalance{
+		Owner:        tb.Owner.Hex(),
+		TokenAddress: tb.TokenAddress.Hex(),
+		Symbol:       tb.Symbol,
+		Success:      tb.Success,
+	}
+	if tb.Balance != nil {
+		pb.Balance = tb.Balance.Text('f', -1)
+	}
+	return pb
+}
+
+func tokenTypeName(t multicall.TokenType) string {
+	switch t {
+	case multicall.TokenTypeERC20:
+		return "erc20"
+	case multicall.TokenTypeERC721:
+		return "erc721"
+	default:
+		return "native"
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/grpcapi"
+	"chain-lens/modules/erc20"
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeNode 进程内的假节点：余额 = 地址最后一个字节 (ETH)，eth_call 模拟一个 ERC20
+type fakeNode struct{}
+
+type fakeCallArgs struct {
+	To   common.Address `json:"to"`
+	Data hexutil.Bytes  `json:"data"`
+}
+
+func (fakeNode) ChainId() *hexutil.Big       { return (*hexutil.Big)(big.NewInt(31337)) }
+func (fakeNode) BlockNumber() hexutil.Uint64 { return 100 }
+
+func (fakeNode) GetBalance(addr common.Address, block string) *hexutil.Big {
+	wei := new(big.Int).Mul(big.NewInt(int64(addr[19])), big.NewInt(1e18))
+	return (*hexutil.Big)(wei)
+}
+
+func (fakeNode) Call(args fakeCallArgs, block string) (hexutil.Bytes, error) {
+	parsed, _ := erc20.TokenMetaData.GetAbi()
+	uint256 := parsed.Methods["balanceOf"].Outputs
+	outputs := map[string]func() ([]byte, error){
+		"decimals()":    func() ([]byte, error) { return uint256.Pack(big.NewInt(6)) },
+		"totalSupply()": func() ([]byte, error) { return uint256.Pack(big.NewInt(1_000_000)) },
+		"symbol()":      func() ([]byte, error) { return parsed.Methods["symbol"].Outputs.Pack("TKN") },
+		"name()":        func() ([]byte, error) { return parsed.Methods["name"].Outputs.Pack("Test Token") },
+	}
+	for sig, pack := range outputs {
+		if len(args.Data) >= 4 && string(crypto.Keccak256([]byte(sig))[:4]) == string(args.Data[:4]) {
+			return pack()
+		}
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func TestGRPCService(t *testing.T) {
+	node := rpc.NewServer()
+	if err := node.RegisterName("eth", fakeNode{}); err != nil {
+		t.Fatal(err)
+	}
+	httpNode := httptest.NewServer(node)
+	defer httpNode.Close()
+
+	cfg := Config{RpcURL: httpNode.URL, TokenType: "native", Strategy: "rpc-batch"}
+	pool, err := core.NewClientPool(rpcURLs(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	pool.SetChain(core.LookupChain(pool.ChainID, nil))
+	e, err := newEngine(cfg, pool, limits{concurrency: 1, maxWallets: 5, maxBlockRange: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpcapi.RegisterBalanceServiceServer(server, &grpcServer{engine: e})
+	go server.Serve(lis)
+	defer server.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := grpcapi.NewBalanceServiceClient(conn)
+
+	var wallets []string
+	for i := 1; i <= 5; i++ {
+		wallets = append(wallets, common.BigToAddress(big.NewInt(int64(i))).Hex())
+	}
+	stream, err := client.BatchBalances(context.Background(), &grpcapi.BatchBalancesRequest{Wallets: wallets, BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches, got := 0, map[string]string{}
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		batches++
+		if batch.Block != 100 || batch.ChainId != 31337 || batch.Total != 5 {
+			t.Fatalf("unexpected batch header: %v", batch)
+		}
+		for _, b := range batch.Balances {
+			got[b.Owner] = b.Balance
+		}
+	}
+	if batches != 3 || len(got) != 5 || got[wallets[2]] != "3" {
+		t.Fatalf("unexpected stream result: %d batches, %v", batches, got)
+	}
+
+	if _, err := firstBatch(client, "bad"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected invalid argument, got %v", err)
+	}
+
+	// 超过 max-wallets 的请求和无界的日志扫描都被拒绝
+	tooMany, err := client.BatchBalances(context.Background(), &grpcapi.BatchBalancesRequest{Wallets: append(wallets, wallets[0])})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tooMany.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected invalid argument for too many wallets, got %v", err)
+	}
+	erc20Asset := &grpcapi.Asset{TokenType: "erc20", TokenAddress: "0x00000000000000000000000000000000000000aa"}
+	for _, from := range []uint64{0, 40} { // 到最新区块 100：101 / 61 个区块，上限 50
+		holders, err := client.ScanHolders(context.Background(), &grpcapi.ScanHoldersRequest{Asset: erc20Asset, FromBlock: from})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := holders.Recv(); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("from_block %d: expected invalid argument for unbounded range, got %v", from, err)
+		}
+	}
+
+	info, err := client.DetectToken(context.Background(), &grpcapi.DetectTokenRequest{TokenAddress: "0x00000000000000000000000000000000000000aa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TokenType != "erc20" || info.Symbol != "TKN" || info.Decimals != 6 || info.TotalSupply != "1000000" {
+		t.Fatalf("unexpected token info: %v", info)
+	}
+
+	// 并发令牌被占满时 DetectToken 同样排队，超时后返回 RESOURCE_EXHAUSTED
+	e.sem <- struct{}{}
+	e.limits.timeout = 50 * time.Millisecond
+	if _, err := client.DetectToken(context.Background(), &grpcapi.DetectTokenRequest{TokenAddress: "0x00000000000000000000000000000000000000aa"}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected resource exhausted while busy, got %v", err)
+	}
+	<-e.sem
+}
+
+// firstBatch 服务端流式调用的参数错误在第一次 Recv 时返回
+func firstBatch(client grpcapi.BalanceServiceClient, wallet string) (*grpcapi.BalanceBatch, error) {
+	stream, err := client.BatchBalances(context.Background(), &grpcapi.BatchBalancesRequest{Wallets: []string{wallet}})
+	if err != nil {
+		return nil, err
+	}
+	return stream.Recv()
+}
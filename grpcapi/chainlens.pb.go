@@ -0,0 +1,622 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: chainlens.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Asset 要查询的资产，为空时使用服务端配置文件里的代币
+type Asset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddress  string                 `protobuf:"bytes,1,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	TokenType     string                 `protobuf:"bytes,2,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"` // native / erc20 / erc721
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Asset) Reset() {
+	*x = Asset{}
+	mi := &file_chainlens_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Asset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Asset) ProtoMessage() {}
+
+func (x *Asset) ProtoReflect() protoreflect.Message {
+	mi := &file_chainlens_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Asset.ProtoReflect.Descriptor instead.
+func (*Asset) Descriptor() ([]byte, []int) {
+	return file_chainlens_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Asset) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *Asset) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+type BatchBalancesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Wallets       []string               `protobuf:"bytes,1,rep,name=wallets,proto3" json:"wallets,omitempty"`
+	Asset         *Asset                 `protobuf:"bytes,2,opt,name=asset,proto3" json:"asset,omitempty"`
+	Block         uint64                 `protobuf:"varint,3,opt,name=block,proto3" json:"block,omitempty"`                          // 0 表示最新区块
+	BatchSize     uint32                 `protobuf:"varint,4,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"` // 每批推送的钱包数，0 使用默认值
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchBalancesRequest) Reset() {
+	*x = BatchBalancesRequest{}
+	mi := &file_chainlens_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchBalancesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchBalancesRequest) ProtoMessage() {}
+
+func (x *BatchBalancesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chainlens_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchBalancesRequest.ProtoReflect.Descriptor instead.
+func (*BatchBalancesRequest) Descriptor() ([]byte, []int) {
+	return file_chainlens_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchBalancesRequest) GetWallets() []string {
+	if x != nil {
+		return x.Wallets
+	}
+	return nil
+}
+
+func (x *BatchBalancesRequest) GetAsset() *Asset {
+	if x != nil {
+		return x.Asset
+	}
+	return nil
+}
+
+func (x *BatchBalancesRequest) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *BatchBalancesRequest) GetBatchSize() uint32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+type ScanHoldersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Asset         *Asset                 `protobuf:"bytes,1,opt,name=asset,proto3" json:"asset,omitempty"`
+	FromBlock     uint64                 `protobuf:"varint,2,opt,name=from_block,json=fromBlock,proto3" json:"from_block,omitempty"`
+	ToBlock       uint64                 `protobuf:"varint,3,opt,name=to_block,json=toBlock,proto3" json:"to_block,omitempty"` // 0 表示最新区块
+	Chunk         uint64                 `protobuf:"varint,4,opt,name=chunk,proto3" json:"chunk,omitempty"`                    // 扫描日志的初始分段大小，0 使用默认值
+	BatchSize     uint32                 `protobuf:"varint,5,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanHoldersRequest) Reset() {
+	*x = ScanHoldersRequest{}
+	mi := &file_chainlens_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanHoldersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanHoldersRequest) ProtoMessage() {}
+
+func (x *ScanHoldersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chainlens_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanHoldersRequest.ProtoReflect.Descriptor instead.
+func (*ScanHoldersRequest) Descriptor() ([]byte, []int) {
+	return file_chainlens_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScanHoldersRequest) GetAsset() *Asset {
+	if x != nil {
+		return x.Asset
+	}
+	return nil
+}
+
+func (x *ScanHoldersRequest) GetFromBlock() uint64 {
+	if x != nil {
+		return x.FromBlock
+	}
+	return 0
+}
+
+func (x *ScanHoldersRequest) GetToBlock() uint64 {
+	if x != nil {
+		return x.ToBlock
+	}
+	return 0
+}
+
+func (x *ScanHoldersRequest) GetChunk() uint64 {
+	if x != nil {
+		return x.Chunk
+	}
+	return 0
+}
+
+func (x *ScanHoldersRequest) GetBatchSize() uint32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+// TokenBalance 对应 core.TokenBalance，余额为十进制字符串
+type TokenBalance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Owner         string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	TokenAddress  string                 `protobuf:"bytes,2,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	Symbol        string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Balance       string                 `protobuf:"bytes,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	Success       bool                   `protobuf:"varint,5,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenBalance) Reset() {
+	*x = TokenBalance{}
+	mi := &file_chainlens_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenBalance) ProtoMessage() {}
+
+func (x *TokenBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_chainlens_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenBalance.ProtoReflect.Descriptor instead.
+func (*TokenBalance) Descriptor() ([]byte, []int) {
+	return file_chainlens_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TokenBalance) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *TokenBalance) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *TokenBalance) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *TokenBalance) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *TokenBalance) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type BalanceBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Block         uint64                 `protobuf:"varint,2,opt,name=block,proto3" json:"block,omitempty"`
+	Balances      []*TokenBalance        `protobuf:"bytes,3,rep,name=balances,proto3" json:"balances,omitempty"`
+	Done          uint32                 `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`   // 已推送的钱包数
+	Total         uint32                 `protobuf:"varint,5,opt,name=total,proto3" json:"total,omitempty"` // 钱包总数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceBatch) Reset() {
+	*x = BalanceBatch{}
+	mi := &file_chainlens_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceBatch) ProtoMessage() {}
+
+func (x *BalanceBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_chainlens_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceBatch.ProtoReflect.Descriptor instead.
+func (*BalanceBatch) Descriptor() ([]byte, []int) {
+	return file_chainlens_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BalanceBatch) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *BalanceBatch) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *BalanceBatch) GetBalances() []*TokenBalance {
+	if x != nil {
+		return x.Balances
+	}
+	return nil
+}
+
+func (x *BalanceBatch) GetDone() uint32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+func (x *BalanceBatch) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type DetectTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddress  string                 `protobuf:"bytes,1,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	Block         uint64                 `protobuf:"varint,2,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetectTokenRequest) Reset() {
+	*x = DetectTokenRequest{}
+	mi := &file_chainlens_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectTokenRequest) ProtoMessage() {}
+
+func (x *DetectTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chainlens_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectTokenRequest.ProtoReflect.Descriptor instead.
+func (*DetectTokenRequest) Descriptor() ([]byte, []int) {
+	return file_chainlens_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DetectTokenRequest) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *DetectTokenRequest) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+type TokenInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddress  string                 `protobuf:"bytes,1,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	TokenType     string                 `protobuf:"bytes,2,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Symbol        string                 `protobuf:"bytes,4,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Decimals      uint32                 `protobuf:"varint,5,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	TotalSupply   string                 `protobuf:"bytes,6,opt,name=total_supply,json=totalSupply,proto3" json:"total_supply,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenInfo) Reset() {
+	*x = TokenInfo{}
+	mi := &file_chainlens_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenInfo) ProtoMessage() {}
+
+func (x *TokenInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_chainlens_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenInfo.ProtoReflect.Descriptor instead.
+func (*TokenInfo) Descriptor() ([]byte, []int) {
+	return file_chainlens_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TokenInfo) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetDecimals() uint32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetTotalSupply() string {
+	if x != nil {
+		return x.TotalSupply
+	}
+	return ""
+}
+
+var File_chainlens_proto protoreflect.FileDescriptor
+
+const file_chainlens_proto_rawDesc = "" +
+	"\n" +
+	"\x0fchainlens.proto\x12\fchainlens.v1\"K\n" +
+	"\x05Asset\x12#\n" +
+	"\rtoken_address\x18\x01 \x01(\tR\ftokenAddress\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x02 \x01(\tR\ttokenType\"\x90\x01\n" +
+	"\x14BatchBalancesRequest\x12\x18\n" +
+	"\awallets\x18\x01 \x03(\tR\awallets\x12)\n" +
+	"\x05asset\x18\x02 \x01(\v2\x13.chainlens.v1.AssetR\x05asset\x12\x14\n" +
+	"\x05block\x18\x03 \x01(\x04R\x05block\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x04 \x01(\rR\tbatchSize\"\xae\x01\n" +
+	"\x12ScanHoldersRequest\x12)\n" +
+	"\x05asset\x18\x01 \x01(\v2\x13.chainlens.v1.AssetR\x05asset\x12\x1d\n" +
+	"\n" +
+	"from_block\x18\x02 \x01(\x04R\tfromBlock\x12\x19\n" +
+	"\bto_block\x18\x03 \x01(\x04R\atoBlock\x12\x14\n" +
+	"\x05chunk\x18\x04 \x01(\x04R\x05chunk\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x05 \x01(\rR\tbatchSize\"\x95\x01\n" +
+	"\fTokenBalance\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x12#\n" +
+	"\rtoken_address\x18\x02 \x01(\tR\ftokenAddress\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\x12\x18\n" +
+	"\abalance\x18\x04 \x01(\tR\abalance\x12\x18\n" +
+	"\asuccess\x18\x05 \x01(\bR\asuccess\"\xa1\x01\n" +
+	"\fBalanceBatch\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x14\n" +
+	"\x05block\x18\x02 \x01(\x04R\x05block\x126\n" +
+	"\bbalances\x18\x03 \x03(\v2\x1a.chainlens.v1.TokenBalanceR\bbalances\x12\x12\n" +
+	"\x04done\x18\x04 \x01(\rR\x04done\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\rR\x05total\"O\n" +
+	"\x12DetectTokenRequest\x12#\n" +
+	"\rtoken_address\x18\x01 \x01(\tR\ftokenAddress\x12\x14\n" +
+	"\x05block\x18\x02 \x01(\x04R\x05block\"\xba\x01\n" +
+	"\tTokenInfo\x12#\n" +
+	"\rtoken_address\x18\x01 \x01(\tR\ftokenAddress\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x02 \x01(\tR\ttokenType\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x16\n" +
+	"\x06symbol\x18\x04 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\bdecimals\x18\x05 \x01(\rR\bdecimals\x12!\n" +
+	"\ftotal_supply\x18\x06 \x01(\tR\vtotalSupply2\xfc\x01\n" +
+	"\x0eBalanceService\x12Q\n" +
+	"\rBatchBalances\x12\".chainlens.v1.BatchBalancesRequest\x1a\x1a.chainlens.v1.BalanceBatch0\x01\x12M\n" +
+	"\vScanHolders\x12 .chainlens.v1.ScanHoldersRequest\x1a\x1a.chainlens.v1.BalanceBatch0\x01\x12H\n" +
+	"\vDetectToken\x12 .chainlens.v1.DetectTokenRequest\x1a\x17.chainlens.v1.TokenInfoB\x1cZ\x1achain-lens/grpcapi;grpcapib\x06proto3"
+
+var (
+	file_chainlens_proto_rawDescOnce sync.Once
+	file_chainlens_proto_rawDescData []byte
+)
+
+func file_chainlens_proto_rawDescGZIP() []byte {
+	file_chainlens_proto_rawDescOnce.Do(func() {
+		file_chainlens_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_chainlens_proto_rawDesc), len(file_chainlens_proto_rawDesc)))
+	})
+	return file_chainlens_proto_rawDescData
+}
+
+var file_chainlens_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_chainlens_proto_goTypes = []any{
+	(*Asset)(nil),                // 0: chainlens.v1.Asset
+	(*BatchBalancesRequest)(nil), // 1: chainlens.v1.BatchBalancesRequest
+	(*ScanHoldersRequest)(nil),   // 2: chainlens.v1.ScanHoldersRequest
+	(*TokenBalance)(nil),         // 3: chainlens.v1.TokenBalance
+	(*BalanceBatch)(nil),         // 4: chainlens.v1.BalanceBatch
+	(*DetectTokenRequest)(nil),   // 5: chainlens.v1.DetectTokenRequest
+	(*TokenInfo)(nil),            // 6: chainlens.v1.TokenInfo
+}
+var file_chainlens_proto_depIdxs = []int32{
+	0, // 0: chainlens.v1.BatchBalancesRequest.asset:type_name -> chainlens.v1.Asset
+	0, // 1: chainlens.v1.ScanHoldersRequest.asset:type_name -> chainlens.v1.Asset
+	3, // 2: chainlens.v1.BalanceBatch.balances:type_name -> chainlens.v1.TokenBalance
+	1, // 3: chainlens.v1.BalanceService.BatchBalances:input_type -> chainlens.v1.BatchBalancesRequest
+	2, // 4: chainlens.v1.BalanceService.ScanHolders:input_type -> chainlens.v1.ScanHoldersRequest
+	5, // 5: chainlens.v1.BalanceService.DetectToken:input_type -> chainlens.v1.DetectTokenRequest
+	4, // 6: chainlens.v1.BalanceService.BatchBalances:output_type -> chainlens.v1.BalanceBatch
+	4, // 7: chainlens.v1.BalanceService.ScanHolders:output_type -> chainlens.v1.BalanceBatch
+	6, // 8: chainlens.v1.BalanceService.DetectToken:output_type -> chainlens.v1.TokenInfo
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_chainlens_proto_init() }
+func file_chainlens_proto_init() {
+	if File_chainlens_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chainlens_proto_rawDesc), len(file_chainlens_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_chainlens_proto_goTypes,
+		DependencyIndexes: file_chainlens_proto_depIdxs,
+		MessageInfos:      file_chainlens_proto_msgTypes,
+	}.Build()
+	File_chainlens_proto = out.File
+	file_chainlens_proto_goTypes = nil
+	file_chainlens_proto_depIdxs = nil
+}
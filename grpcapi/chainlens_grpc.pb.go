@@ -0,0 +1,214 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: chainlens.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BalanceService_BatchBalances_FullMethodName = "/chainlens.v1.BalanceService/BatchBalances"
+	BalanceService_ScanHolders_FullMethodName   = "/chainlens.v1.BalanceService/ScanHolders"
+	BalanceService_DetectToken_FullMethodName   = "/chainlens.v1.BalanceService/DetectToken"
+)
+
+// BalanceServiceClient is the client API for BalanceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BalanceService 余额查询服务，和 CLI 使用同一套查询逻辑
+type BalanceServiceClient interface {
+	// BatchBalances 批量查询钱包余额，每完成一批就推送一次结果
+	BatchBalances(ctx context.Context, in *BatchBalancesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BalanceBatch], error)
+	// ScanHolders 扫描 Transfer 事件发现持有人，再按批推送持有人余额
+	ScanHolders(ctx context.Context, in *ScanHoldersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BalanceBatch], error)
+	// DetectToken 识别代币类型 (ERC20 / ERC721) 并读取基础信息
+	DetectToken(ctx context.Context, in *DetectTokenRequest, opts ...grpc.CallOption) (*TokenInfo, error)
+}
+
+type balanceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBalanceServiceClient(cc grpc.ClientConnInterface) BalanceServiceClient {
+	return &balanceServiceClient{cc}
+}
+
+func (c *balanceServiceClient) BatchBalances(ctx context.Context, in *BatchBalancesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BalanceBatch], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BalanceService_ServiceDesc.Streams[0], BalanceService_BatchBalances_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BatchBalancesRequest, BalanceBatch]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BalanceService_BatchBalancesClient = grpc.ServerStreamingClient[BalanceBatch]
+
+func (c *balanceServiceClient) ScanHolders(ctx context.Context, in *ScanHoldersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BalanceBatch], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BalanceService_ServiceDesc.Streams[1], BalanceService_ScanHolders_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ScanHoldersRequest, BalanceBatch]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BalanceService_ScanHoldersClient = grpc.ServerStreamingClient[BalanceBatch]
+
+func (c *balanceServiceClient) DetectToken(ctx context.Context, in *DetectTokenRequest, opts ...grpc.CallOption) (*TokenInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TokenInfo)
+	err := c.cc.Invoke(ctx, BalanceService_DetectToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BalanceServiceServer is the server API for BalanceService service.
+// All implementations must embed UnimplementedBalanceServiceServer
+// for forward compatibility.
+//
+// BalanceService 余额查询服务，和 CLI 使用同一套查询逻辑
+type BalanceServiceServer interface {
+	// BatchBalances 批量查询钱包余额，每完成一批就推送一次结果
+	BatchBalances(*BatchBalancesRequest, grpc.ServerStreamingServer[BalanceBatch]) error
+	// ScanHolders 扫描 Transfer 事件发现持有人，再按批推送持有人余额
+	ScanHolders(*ScanHoldersRequest, grpc.ServerStreamingServer[BalanceBatch]) error
+	// DetectToken 识别代币类型 (ERC20 / ERC721) 并读取基础信息
+	DetectToken(context.Context, *DetectTokenRequest) (*TokenInfo, error)
+	mustEmbedUnimplementedBalanceServiceServer()
+}
+
+// UnimplementedBalanceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBalanceServiceServer struct{}
+
+func (UnimplementedBalanceServiceServer) BatchBalances(*BatchBalancesRequest, grpc.ServerStreamingServer[BalanceBatch]) error {
+	return status.Error(codes.Unimplemented, "method BatchBalances not implemented")
+}
+func (UnimplementedBalanceServiceServer) ScanHolders(*ScanHoldersRequest, grpc.ServerStreamingServer[BalanceBatch]) error {
+	return status.Error(codes.Unimplemented, "method ScanHolders not implemented")
+}
+func (UnimplementedBalanceServiceServer) DetectToken(context.Context, *DetectTokenRequest) (*TokenInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method DetectToken not implemented")
+}
+func (UnimplementedBalanceServiceServer) mustEmbedUnimplementedBalanceServiceServer() {}
+func (UnimplementedBalanceServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeBalanceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BalanceServiceServer will
+// result in compilation errors.
+type UnsafeBalanceServiceServer interface {
+	mustEmbedUnimplementedBalanceServiceServer()
+}
+
+func RegisterBalanceServiceServer(s grpc.ServiceRegistrar, srv BalanceServiceServer) {
+	// If the following call panics, it indicates UnimplementedBalanceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BalanceService_ServiceDesc, srv)
+}
+
+func _BalanceService_BatchBalances_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BatchBalancesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BalanceServiceServer).BatchBalances(m, &grpc.GenericServerStream[BatchBalancesRequest, BalanceBatch]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BalanceService_BatchBalancesServer = grpc.ServerStreamingServer[BalanceBatch]
+
+func _BalanceService_ScanHolders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScanHoldersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BalanceServiceServer).ScanHolders(m, &grpc.GenericServerStream[ScanHoldersRequest, BalanceBatch]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BalanceService_ScanHoldersServer = grpc.ServerStreamingServer[BalanceBatch]
+
+func _BalanceService_DetectToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetectTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BalanceServiceServer).DetectToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BalanceService_DetectToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BalanceServiceServer).DetectToken(ctx, req.(*DetectTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BalanceService_ServiceDesc is the grpc.ServiceDesc for BalanceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BalanceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "chainlens.v1.BalanceService",
+	HandlerType: (*BalanceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DetectToken",
+			Handler:    _BalanceService_DetectToken_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchBalances",
+			Handler:       _BalanceService_BatchBalances_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ScanHolders",
+			Handler:       _BalanceService_ScanHolders_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "chainlens.proto",
+}
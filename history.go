@@ -0,0 +1,160 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/metrics"
+	"chain-lens/notify"
+	"chain-lens/store"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// saveRun 把本次运行的配置和余额明细写入 SQLite 结果库
+func saveRun(cfg Config, run store.Run, balances []core.TokenBalance) error {
+	db, err := store.Open(cfg.DBPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	cfgJSON, err := json.Marshal(redactConfig(cfg))
+	if err != nil {
+		return err
+	}
+	run.Config = string(cfgJSON)
+
+	runID, err := db.SaveRun(run, balances)
+	if err != nil {
+		return err
+	}
+	logger.Info("run saved", "run", runID, "db", cfg.DBPath, "block", run.Block)
+	return nil
+}
+
+// redactConfig 返回去掉密钥的配置副本：RPC / WebSocket / webhook 地址里常带 API key，
+// 只保留 scheme://host (与指标的 endpoint 标签一致)，webhook 签名密钥直接清空
+func redactConfig(cfg Config) Config {
+	cfg.RpcURL = redactURL(cfg.RpcURL)
+	cfg.RpcURLs = redactURLs(cfg.RpcURLs)
+	networks := make([]NetworkConfig, len(cfg.Networks))
+	for i, network := range cfg.Networks {
+		network.RpcURLs = redactURLs(network.RpcURLs)
+		networks[i] = network
+	}
+	cfg.Networks = networks
+	cfg.Watch.WsURL = redactURL(cfg.Watch.WsURL)
+	cfg.Watch.Webhook = redactURL(cfg.Watch.Webhook)
+	if cfg.Notify != nil {
+		n := *cfg.Notify
+		n.Webhooks = make([]notify.Webhook, len(cfg.Notify.Webhooks))
+		for i, hook := range cfg.Notify.Webhooks {
+			n.Webhooks[i] = notify.Webhook{URL: redactURL(hook.URL), SecretEnv: hook.SecretEnv}
+		}
+		cfg.Notify = &n
+	}
+	return cfg
+}
+
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	return metrics.EndpointLabel(rawURL)
+}
+
+func redactURLs(urls []string) []string {
+	out := make([]string, len(urls))
+	for i, u := range urls {
+		out[i] = redactURL(u)
+	}
+	return out
+}
+
+// runHistory history 子命令：列出历史运行，或查询某个钱包在各次运行中的余额变化
+//
+//	chain-lens history -db results.db
+//	chain-lens history -db results.db -wallet 0x... [-token 0x...]
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	dbPath := fs.String("db", "results.db", "SQLite 结果库路径")
+	wallet := fs.String("wallet", "", "只看这个钱包在各次运行中的余额")
+	token := fs.String("token", "", "配合 -wallet 使用，只看这个代币")
+	limit := fs.Int("limit", 20, "最多列出多少次运行 (0 表示全部)")
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	db, err := store.Open(*dbPath)
+	if err != nil {
+		fatal(i18n.Errorf("err.open_store", err))
+	}
+	defer db.Close()
+
+	if *wallet == "" {
+		printRuns(db, *limit)
+		return
+	}
+
+	if !common.IsHexAddress(*wallet) {
+		fatal(i18n.Errorf("err.invalid_wallet", *wallet))
+	}
+	var tokenAddr *common.Address
+	if *token != "" {
+		if !common.IsHexAddress(*token) {
+			fatal(i18n.Errorf("err.invalid_token", *token))
+		}
+		addr := common.HexToAddress(*token)
+		tokenAddr = &addr
+	}
+	printWalletHistory(db, common.HexToAddress(*wallet), tokenAddr)
+}
+
+func printRuns(db *store.Store, limit int) {
+	runs, err := db.ListRuns(limit)
+	if err != nil {
+		fatal(i18n.Errorf("err.list_runs", err))
+	}
+	if len(runs) == 0 {
+		fmt.Println(i18n.T("report.history.no_runs"))
+		return
+	}
+	fmt.Printf("%-6s %-20s %-8s %-10s %-8s %-12s %-10s\n", "RUN", "TIME", "CHAIN", "BLOCK", "SYMBOL", "SUCCESS", "DURATION")
+	for _, r := range runs {
+		fmt.Printf("%-6d %-20s %-8d %-10d %-8s %-12s %-10s\n",
+			r.ID, r.StartedAt.Format("2006-01-02 15:04:05"), r.ChainID, r.Block, r.Symbol,
+			fmt.Sprintf("%d/%d", r.SuccessCount, r.WalletCount), r.Duration)
+	}
+}
+
+func printWalletHistory(db *store.Store, wallet common.Address, token *common.Address) {
+	history, err := db.WalletHistory(wallet, token)
+	if err != nil {
+		fatal(i18n.Errorf("err.wallet_history", err))
+	}
+	if len(history) == 0 {
+		fmt.Println(i18n.T("report.history.no_records", wallet.Hex()))
+		return
+	}
+	fmt.Println(i18n.T("report.history.title", wallet.Hex()))
+	// 按代币记住上一次的余额，用来标记变化
+	last := make(map[common.Address]string)
+	for _, h := range history {
+		balance := h.Balance
+		if !h.Success {
+			balance = i18n.T("report.history.failed")
+		}
+		mark := ""
+		if prev, ok := last[h.TokenAddress]; ok && h.Success && prev != h.Balance {
+			mark = i18n.T("report.history.changed", prev)
+		}
+		if h.Success {
+			last[h.TokenAddress] = h.Balance
+		}
+		fmt.Printf("#%-5d %s | block %-10d | %s %s %s\n",
+			h.RunID, h.StartedAt.Format("2006-01-02 15:04:05"), h.Block, balance, h.Symbol, mark)
+	}
+}
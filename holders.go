@@ -0,0 +1,136 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/modules/holders"
+	"chain-lens/report"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// runHolders holders 子命令：扫描 Transfer 事件找出所有出现过的地址，
+// 再在 -to 区块批量查询当前余额，输出完整的持有人列表。
+//
+//	chain-lens holders -from 18000000 [-to 19000000] [-out holders.json]
+func runHolders(args []string) {
+	fs := flag.NewFlagSet("holders", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	fromBlock := fs.Uint64("from", 0, "起始区块 (建议填代币部署区块)")
+	toBlock := fs.Uint64("to", 0, "结束区块 (0 表示当前最新区块)")
+	chunk := fs.Uint64("chunk", core.DefaultLogChunk, "每次 eth_getLogs 的初始区块数，遇到节点限制会自动缩小")
+	outPath := fs.String("out", "", "把持有人余额写成 JSON 快照")
+	limit := fs.Int("limit", 100, "最多打印多少个持有人 (0 表示全部)")
+	stats := fs.Bool("stats", false, "输出持有人分布统计 (Top N、基尼系数、分位数、直方图)")
+	topN := fs.Int("top", report.DefaultTopN, "统计模式下展示的头部持有人数量")
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	cfg := loadConfig(*configPath)
+	cfg.TopN = *topN
+	tokenType, err := ParseTokenType(cfg.TokenType)
+	if err != nil {
+		fatal(err)
+	}
+	tokenAddr := common.HexToAddress(cfg.TokenAddress)
+
+	client, err := connect(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	defer client.Close()
+
+	to := *toBlock
+	if to == 0 {
+		to, err = client.Client.BlockNumber(context.Background())
+		if err != nil {
+			fatal(i18n.Errorf("err.block_number", err))
+		}
+	}
+	if *fromBlock > to {
+		fatal(i18n.Errorf("err.block_range", *fromBlock, to))
+	}
+
+	scanner, err := holders.NewScanner(client, tokenType, tokenAddr)
+	if err != nil {
+		fatal(err)
+	}
+	scanner.Chunk = *chunk
+
+	startTime := time.Now()
+	logger.Info("scanning Transfer logs", "token", tokenAddr, "from_block", *fromBlock, "to_block", to)
+	addresses, err := scanner.Collect(*fromBlock, to)
+	if err != nil {
+		fatal(i18n.Errorf("err.scan_transfers", err))
+	}
+	logger.Info("addresses found in Transfer logs", "count", len(addresses))
+	if len(addresses) == 0 {
+		return
+	}
+
+	block := new(big.Int).SetUint64(to)
+	balances := collectBalances(cfg, client, addresses, block)
+
+	// 只保留当前余额大于 0 的地址，按余额从大到小排序
+	var holderBalances []core.TokenBalance
+	failed := 0
+	for _, tb := range balances {
+		if !tb.Success {
+			failed++
+			continue
+		}
+		if tb.Balance != nil && tb.Balance.Sign() > 0 {
+			holderBalances = append(holderBalances, tb)
+		}
+	}
+	sort.SliceStable(holderBalances, func(i, j int) bool {
+		return holderBalances[i].Balance.Cmp(holderBalances[j].Balance) > 0
+	})
+
+	total := new(big.Float)
+	for i, tb := range holderBalances {
+		total.Add(total, tb.Balance)
+		if *limit == 0 || i < *limit {
+			fmt.Printf("🏅 [%d] %s | %.4f %s\n", i+1, tb.Owner.Hex(), tb.Balance, tb.Symbol)
+		}
+	}
+	symbol := ""
+	if len(holderBalances) > 0 {
+		symbol = holderBalances[0].Symbol
+	}
+
+	fmt.Printf("\n--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.holders.title", to))
+	fmt.Printf("--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.holders.seen", len(addresses)))
+	fmt.Println(i18n.T("report.holders.current", len(holderBalances)))
+	if failed > 0 {
+		fmt.Println(i18n.T("report.holders.failed", failed))
+	}
+	fmt.Println(i18n.T("report.holders.total", total, symbol))
+	fmt.Println(i18n.T("report.holders.time", time.Since(startTime)))
+	fmt.Printf("--------------------------------------------------\n")
+
+	var holderStats *report.Stats
+	if *stats {
+		holderStats = buildStats(cfg, client, block, holderBalances)
+		printStats(holderStats, symbol)
+	}
+
+	if *outPath != "" {
+		snap := report.NewSnapshot(client.ChainID.Int64(), to, tokenAddr, holderBalances)
+		snap.Stats = holderStats
+		if err := report.WriteJSON(*outPath, snap); err != nil {
+			logger.Warn("failed to write snapshot", "path", *outPath, "err", err)
+		} else {
+			logger.Info("snapshot written", "path", *outPath)
+		}
+	}
+}
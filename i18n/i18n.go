@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Lang 界面语言
+type Lang string
+
+const (
+	English Lang = "en"
+	Chinese Lang = "zh"
+)
+
+var current atomic.Value // Lang
+
+func init() {
+	current.Store(English)
+}
+
+// ParseLang 解析语言标识，接受 en / zh 以及 LANG 风格的写法 (zh_CN.UTF-8、en-US、C、POSIX)
+func ParseLang(s string) (Lang, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(s, "_-.@"); i >= 0 {
+		s = s[:i]
+	}
+	switch s {
+	case "en", "c", "posix":
+		return English, nil
+	case "zh":
+		return Chinese, nil
+	}
+	return "", fmt.Errorf("unsupported language: %s (valid: en, zh)", s)
+}
+
+// Detect 按优先级选择语言：显式指定 (-lang) > CHAIN_LENS_LANG > LC_ALL > LC_MESSAGES > LANG，
+// 都没有或无法识别时使用英文
+func Detect(explicit string) Lang {
+	for _, v := range []string{explicit, os.Getenv("CHAIN_LENS_LANG"), os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")} {
+		if v == "" {
+			continue
+		}
+		if lang, err := ParseLang(v); err == nil {
+			return lang
+		}
+	}
+	return English
+}
+
+// SetLang 设置全局界面语言
+func SetLang(lang Lang) {
+	current.Store(lang)
+}
+
+// Current 当前界面语言
+func Current() Lang {
+	return current.Load().(Lang)
+}
+
+// T 取出 key 在当前语言下的文案并用 args 格式化。缺少译文时退回英文，key 不存在时原样返回 key。
+func T(key string, args ...any) string {
+	return Translate(Current(), key, args...)
+}
+
+// Translate 取出 key 在指定语言下的文案
+func Translate(lang Lang, key string, args ...any) string {
+	m, ok := catalog[key]
+	if !ok {
+		return key
+	}
+	tmpl := m.en
+	if lang == Chinese && m.zh != "" {
+		tmpl = m.zh
+	}
+	if len(args) == 0 {
+		return tmpl
+	}
+	return fmt.Sprintf(tmpl, args...)
+}
+
+// Error 本地化的错误。Key 不随语言变化，调用方 (脚本、日志检索) 应按 Key 匹配，而不是按文案。
+type Error struct {
+	Key  string
+	Args []any
+}
+
+// Errorf 创建 key 对应的本地化错误，args 里的 error 可以用 errors.Is / errors.As 取出
+func Errorf(key string, args ...any) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+func (e *Error) Error() string {
+	return T(e.Key, e.Args...)
+}
+
+func (e *Error) Unwrap() []error {
+	var errs []error
+	for _, a := range e.Args {
+		if err, ok := a.(error); ok {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Code 取出错误链里第一个本地化错误的 key，没有时返回空字符串
+func Code(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Key
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+var verbRe = regexp.MustCompile(`%[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+// 每条文案都要有中英文，且格式化动词的顺序一致，否则切换语言后参数会错位
+func TestCatalog(t *testing.T) {
+	for key, m := range catalog {
+		if m.en == "" || m.zh == "" {
+			t.Errorf("%s: missing translation", key)
+			continue
+		}
+		if en, zh := verbRe.FindAllString(m.en, -1), verbRe.FindAllString(m.zh, -1); !slices.Equal(en, zh) {
+			t.Errorf("%s: verbs differ: en %v, zh %v", key, en, zh)
+		}
+	}
+}
+
+func TestLang(t *testing.T) {
+	cases := map[string]Lang{"zh_CN.UTF-8": Chinese, "zh-TW": Chinese, "en_US.UTF-8": English, "C": English, "ZH": Chinese}
+	for in, want := range cases {
+		if got, err := ParseLang(in); err != nil || got != want {
+			t.Errorf("ParseLang(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseLang("fr_FR.UTF-8"); err == nil {
+		t.Error("unsupported language must be rejected")
+	}
+
+	t.Setenv("CHAIN_LENS_LANG", "")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "fr_FR.UTF-8")
+	t.Setenv("LANG", "zh_CN.UTF-8")
+	if got := Detect(""); got != Chinese {
+		t.Errorf("unsupported LC_MESSAGES must fall through to LANG, got %s", got)
+	}
+	if got := Detect("en"); got != English {
+		t.Errorf("explicit language must win, got %s", got)
+	}
+}
+
+func TestError(t *testing.T) {
+	defer SetLang(Current())
+	err := Errorf("err.read_file", io.ErrUnexpectedEOF)
+
+	SetLang(English)
+	if got := err.Error(); got != "cannot read file: unexpected EOF" {
+		t.Errorf("en: %s", got)
+	}
+	SetLang(Chinese)
+	if got := err.Error(); got != "无法读取文件: unexpected EOF" {
+		t.Errorf("zh: %s", got)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("wrapped error must be reachable")
+	}
+	if code := Code(errors.Join(errors.New("context"), err)); code != "err.read_file" {
+		t.Errorf("Code = %q", code)
+	}
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("unknown key must be returned as is, got %s", got)
+	}
+}
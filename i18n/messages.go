@@ -0,0 +1,157 @@
+package i18n
+
+// message 一条文案的各语言版本，格式化动词 (%d、%s ...) 的顺序必须一致
+type message struct {
+	en, zh string
+}
+
+// catalog 所有面向用户的文案。key 一经发布不再改动：错误的 key 会作为 code 输出，脚本按它匹配。
+// 诊断日志 (logger) 的 msg 是固定英文，不在这里。
+var catalog = map[string]message{
+	// 通用错误
+	"err.config_not_found":   {"config file %s not found, make sure it exists in the working directory", "请确保目录下有 %s 文件"},
+	"err.config_invalid":     {"failed to parse %s, check the JSON format: %v", "配置解析失败，请检查 %s 的 json 格式: %v"},
+	"err.read_file":          {"cannot read file: %v", "无法读取文件: %v"},
+	"err.block_number":       {"failed to get block number: %v", "获取区块高度失败: %v"},
+	"err.token_type_empty":   {"configuration error: token_type cannot be empty, valid values are: native, erc20, erc721", "配置错误：token_type 不能为空，可选值：native、erc20、erc721"},
+	"err.token_type_invalid": {"configuration error: invalid token_type %q, valid values are: native, erc20, erc721", "配置错误：无效的 token_type %q，可选值：native、erc20、erc721"},
+	"err.invalid_address":    {"invalid address: %s", "无效地址: %s"},
+	"err.invalid_wallet":     {"invalid wallet address: %s", "无效钱包地址: %s"},
+	"err.invalid_token":      {"invalid token address: %s", "无效代币地址: %s"},
+	"err.network_config":     {"network %s: %v", "网络 %s: %v"},
+
+	// history
+	"err.open_store":     {"cannot open result database: %v", "无法打开结果库: %v"},
+	"err.list_runs":      {"failed to list runs: %v", "查询运行记录失败: %v"},
+	"err.wallet_history": {"failed to query wallet history: %v", "查询钱包历史失败: %v"},
+
+	// diff
+	"err.diff_from_required": {"-from is required", "必须指定 -from"},
+	"err.diff_load_from":     {"failed to load -from snapshot: %v", "读取 -from 快照失败: %v"},
+	"err.diff_load_to":       {"failed to load -to snapshot: %v", "读取 -to 快照失败: %v"},
+
+	// holders / allowances
+	"err.block_range":    {"from block %d is greater than to block %d", "起始区块 %d 大于结束区块 %d"},
+	"err.scan_transfers": {"failed to scan Transfer logs: %v", "扫描 Transfer 事件失败: %v"},
+	"err.scan_approvals": {"failed to scan Approval logs: %v", "扫描 Approval 事件失败: %v"},
+	"err.no_spenders":    {"no spenders: pass -spenders / -spenders-file or use -discover", "没有 spender：请通过 -spenders / -spenders-file 指定，或使用 -discover"},
+	"err.allowances":     {"batch allowance query failed: %v", "批量查询 allowance 失败: %v"},
+
+	// inspect / safes
+	"err.inspect": {"account inspection failed: %v", "地址画像失败: %v"},
+	"err.safes":   {"failed to query Safe info: %v", "查询 Safe 信息失败: %v"},
+
+	// nfts / owners
+	"err.nfts_erc721_only":   {"the nfts command only supports token_type erc721", "nfts 子命令只支持 token_type 为 erc721 的配置"},
+	"err.owners_erc721_only": {"the owners command only supports token_type erc721", "owners 子命令只支持 token_type 为 erc721 的配置"},
+	"err.enumerate_ids":      {"failed to enumerate token IDs: %v", "枚举 Token ID 失败: %v"},
+	"err.ids_required":       {"either -ids or -range is required", "必须指定 -ids 或 -range"},
+	"err.load_token_ids":     {"failed to read token IDs: %v", "读取 Token ID 失败: %v"},
+	"err.owners_of":          {"batch ownerOf query failed: %v", "批量查询 ownerOf 失败: %v"},
+
+	// watch
+	"err.watch_interval": {"invalid poll interval: %s", "无效轮询间隔: %s"},
+	"err.watch_no_sinks": {"no event sink configured (-json / -webhook / -exec / notify.webhooks)", "没有可用的告警出口 (-json / -webhook / -exec / notify.webhooks)"},
+	"err.ws_connect":     {"failed to connect WebSocket: %v", "连接 WebSocket 失败: %v"},
+	"err.detect_token":   {"failed to detect token: %v", "识别代币失败: %v"},
+	"err.seed_balances":  {"failed to query initial balances: %v", "查询初始余额失败: %v"},
+	"err.notify_config":  {"invalid notify config: %v", "通知配置无效: %v"},
+
+	// 默认命令：逐个钱包的结果和汇总
+	"progress.balance":           {"✅ [%d] Address: %s... | Balance: %s %s", "✅ [%d] 地址: %s... | 余额: %s %s"},
+	"progress.bar":               {"%s %5.1f%% | batches %d/%d | wallets %d/%d | failed %d | retries %d | ETA %s", "%s %5.1f%% | 批次 %d/%d | 钱包 %d/%d | 失败 %d | 补查 %d | 剩余 %s"},
+	"report.summary.title":       {"📊 Summary Report", "📊 汇总报告"},
+	"report.success_rate":        {"✅ Success Rate : %d / %d", "✅ 成功率       : %d / %d"},
+	"report.summary.total":       {"💰 Total Balance: %.4f %s", "💰 总余额       : %.4f %s"},
+	"report.summary.total_value": {"💵 Total Value  : $%.2f", "💵 总价值       : $%.2f"},
+	"report.summary.done":        {"🎉 All tasks completed! Success: %d/%d | Time: %v", "🎉 全部完成！成功: %d/%d | 耗时: %v"},
+	"report.price":               {"💲 Price: $%.6f (%s)", "💲 价格: $%.6f (%s)"},
+
+	// stats
+	"report.stats.title":        {"📈 Distribution Statistics", "📈 持有分布统计"},
+	"report.stats.holders":      {"👛 Holders        : %d / %d wallets", "👛 持有人         : %d / %d 个钱包"},
+	"report.stats.failed":       {" (%d failed)", " (%d 个失败)"},
+	"report.stats.held":         {"💰 Held total     : %.4f %s", "💰 持有总量       : %.4f %s"},
+	"report.stats.supply":       {"🏦 Total supply   : %.4f %s (%.2f%% held by these wallets)", "🏦 总供应量       : %.4f %s (这些钱包持有 %.2f%%)"},
+	"report.stats.gini":         {"⚖️ Gini           : %.4f", "⚖️ 基尼系数       : %.4f"},
+	"report.stats.mean_median":  {"📐 Mean / Median  : %.4f / %.4f %s", "📐 均值 / 中位数  : %.4f / %.4f %s"},
+	"report.stats.top":          {"🏆 Top %d holders", "🏆 前 %d 名持有人"},
+	"report.stats.distribution": {"📊 Balance histogram", "📊 余额分布直方图"},
+
+	// history
+	"report.history.no_runs":    {"📭 No runs recorded yet", "📭 还没有运行记录"},
+	"report.history.no_records": {"📭 No records for %s", "📭 %s 没有记录"},
+	"report.history.title":      {"📜 Balance history of %s", "📜 %s 的余额历史"},
+	"report.history.failed":     {"failed", "失败"},
+	"report.history.changed":    {"🔺 changed (was %s)", "🔺 有变化 (之前为 %s)"},
+
+	// diff
+	"report.diff.title":   {"📊 Diff Report: block %d → block %d", "📊 差异报告：区块 %d → 区块 %d"},
+	"report.diff.more":    {"... and %d more changes", "... 另有 %d 条变化"},
+	"report.diff.changed": {"🔁 Changed wallets : %d", "🔁 余额变化钱包    : %d"},
+	"report.diff.new":     {"🆕 New holders     : %d", "🆕 新增持有人      : %d"},
+	"report.diff.exited":  {"👋 Exited holders  : %d", "👋 退出持有人      : %d"},
+	"report.diff.skipped": {"⚠️ Skipped (query failed on one side): %d", "⚠️ 已跳过 (一侧查询失败): %d"},
+	"report.diff.total":   {"💰 Total: %.4f → %.4f (Δ %+.4f %s)", "💰 合计: %.4f → %.4f (Δ %+.4f %s)"},
+
+	// holders
+	"report.holders.title":   {"📊 Holder Report (block %d)", "📊 持有人报告 (区块 %d)"},
+	"report.holders.seen":    {"📂 Addresses seen : %d", "📂 出现过的地址   : %d"},
+	"report.holders.current": {"👛 Current holders: %d", "👛 当前持有人     : %d"},
+	"report.holders.failed":  {"⚠️ Balance failed : %d", "⚠️ 余额查询失败   : %d"},
+	"report.holders.total":   {"💰 Total held     : %.4f %s", "💰 持有总量       : %.4f %s"},
+	"report.holders.time":    {"⏱️ Time           : %v", "⏱️ 耗时           : %v"},
+
+	// allowances
+	"report.allowances.unlimited_amount": {"UNLIMITED", "无限"},
+	"report.allowances.title":            {"📊 Allowance Report (block %d)", "📊 授权报告 (区块 %d)"},
+	"report.allowances.checked":          {"🔍 Pairs checked : %d", "🔍 已检查组合    : %d"},
+	"report.allowances.outstanding":      {"🔸 Outstanding   : %d", "🔸 未清零授权    : %d"},
+	"report.allowances.unlimited":        {"🚨 Unlimited     : %d", "🚨 无限授权      : %d"},
+	"report.allowances.failed":           {"⚠️ Failed        : %d", "⚠️ 查询失败      : %d"},
+	"report.allowances.time":             {"⏱️ Time          : %v", "⏱️ 耗时          : %v"},
+
+	// inspect
+	"progress.inspect.failed":  {"❌ [%d] %s | query failed", "❌ [%d] %s | 查询失败"},
+	"report.inspect.title":     {"🔎 Account Profile (block %d)", "🔎 地址画像 (区块 %d)"},
+	"report.inspect.contract":  {"📜 Contract  : %d (Safe: %d)", "📜 合约      : %d (Safe: %d)"},
+	"report.inspect.failed":    {"⚠️ Failed    : %d", "⚠️ 查询失败  : %d"},
+	"report.inspect.time":      {"⏱️ Time      : %v", "⏱️ 耗时      : %v"},
+	"progress.safes.not_proxy": {" | not a proxy", " | 不是代理合约"},
+
+	// multichain
+	"report.multichain.title":  {"🌐 Multi-chain Report", "🌐 多链报告"},
+	"report.multichain.chain":  {"🔗 %s (chain %d, block %d)", "🔗 %s (链 %d，区块 %d)"},
+	"report.multichain.failed": {" (⚠️ %d failed)", " (⚠️ %d 个失败)"},
+	"report.multichain.totals": {"Σ Cross-chain totals", "Σ 跨链合计"},
+	"report.multichain.time":   {"⏱️ Time: %v", "⏱️ 耗时: %v"},
+
+	// nfts
+	"progress.nfts.failed":     {"❌ [%d] %s | balanceOf failed", "❌ [%d] %s | balanceOf 查询失败"},
+	"progress.nfts.owned":      {"✅ [%d] %s | %d NFTs %s", "✅ [%d] %s | %d 个 NFT %s"},
+	"progress.nfts.mismatch":   {"   ⚠️ balanceOf=%s but found %d token IDs", "   ⚠️ balanceOf=%s，但找到 %d 个 Token ID"},
+	"progress.nfts.attributes": {"   #%s %s | %s | %d attributes", "   #%s %s | %s | %d 个属性"},
+	"report.nfts.title":        {"📊 NFT Report (block %d)", "📊 NFT 报告 (区块 %d)"},
+	"report.nfts.total":        {"🖼️ Total NFTs   : %d", "🖼️ NFT 总数     : %d"},
+	"report.nfts.time":         {"⏱️ Time         : %v", "⏱️ 耗时         : %v"},
+
+	// owners
+	"progress.owners.missing":    {"🔥 #%s | burned or nonexistent", "🔥 #%s | 已销毁或不存在"},
+	"report.owners.title":        {"📊 Ownership Report (block %d)", "📊 持有关系报告 (区块 %d)"},
+	"report.owners.ids":          {"🖼️ Token IDs     : %d", "🖼️ Token ID 数   : %d"},
+	"report.owners.distinct":     {"👛 Distinct owners: %d", "👛 不同持有人     : %d"},
+	"report.owners.missing":      {"🔥 Burned/missing: %d", "🔥 已销毁/缺失    : %d"},
+	"report.owners.more":         {"   ... and %d more owners", "   ... 另有 %d 个持有人"},
+	"report.owners.all_expected": {"🛡️ All existing IDs are held by %s", "🛡️ 所有存在的 ID 都由 %s 持有"},
+	"report.owners.not_expected": {"🚨 %d IDs are NOT held by %s: %s", "🚨 %d 个 ID 不由 %s 持有: %s"},
+	"report.owners.time":         {"⏱️ Time           : %v", "⏱️ 耗时           : %v"},
+
+	// safes
+	"progress.safes.owners":   {"   👥 Owners: %s", "   👥 所有者: %s"},
+	"progress.safes.failed":   {"   ⚠️ %s: query failed", "   ⚠️ %s: 查询失败"},
+	"progress.safes.not_safe": {"➖ %s is not a Safe", "➖ %s 不是 Safe"},
+	"report.safes.title":      {"🔐 Safe Report (block %d)", "🔐 Safe 报告 (区块 %d)"},
+	"report.safes.count":      {"🔐 Safes   : %d / %d", "🔐 Safe 数 : %d / %d"},
+	"report.safes.total":      {"💰 Total   : %.4f %s", "💰 合计    : %.4f %s"},
+	"report.safes.time":       {"⏱️ Time    : %v", "⏱️ 耗时    : %v"},
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/modules/account"
+	"chain-lens/report"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// inspectReport inspect 子命令输出的 JSON 结构
+type inspectReport struct {
+	ChainID  int64                 `json:"chain_id"`
+	Block    uint64                `json:"block"`
+	Profiles []core.AccountProfile `json:"profiles"`
+}
+
+// runInspect inspect 子命令：识别每个地址是 EOA、合约还是 EIP-7702 委托账户，以及是否为 Safe
+//
+//	chain-lens inspect -file wallets.txt -out profiles.json
+func runInspect(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	filePath := fs.String("file", "wallets.txt", "包含钱包地址的文件路径 (每行一个)")
+	outPath := fs.String("out", "", "把结果写成 JSON 文件")
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	cfg := loadConfig(*configPath)
+	addresses, err := loadAddresses(*filePath)
+	if err != nil {
+		fatal(i18n.Errorf("err.read_file", err))
+	}
+	logger.Info("wallet addresses loaded", "count", len(addresses))
+
+	client, err := connect(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	defer client.Close()
+
+	startTime := time.Now()
+	blockNumber, err := client.Client.BlockNumber(context.Background())
+	if err != nil {
+		fatal(i18n.Errorf("err.block_number", err))
+	}
+	profiles, err := inspectAccounts(cfg, client, addresses, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		fatal(i18n.Errorf("err.inspect", err))
+	}
+
+	counts := map[core.AccountKind]int{}
+	safes, failed := 0, 0
+	for i, p := range profiles {
+		if !p.Success {
+			failed++
+			fmt.Println(i18n.T("progress.inspect.failed", i+1, p.Address.Hex()))
+			continue
+		}
+		counts[p.Kind]++
+		if p.Wallet == "safe" {
+			safes++
+		}
+		fmt.Printf("%s [%d] %s | %s\n", kindIcon(p.Kind), i+1, p.Address.Hex(), describeProfile(p, client.Chain.NativeSymbol))
+	}
+
+	fmt.Printf("\n--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.inspect.title", blockNumber))
+	fmt.Printf("--------------------------------------------------\n")
+	fmt.Printf("👤 EOA       : %d\n", counts[core.AccountEOA])
+	fmt.Printf("🔗 EIP-7702  : %d\n", counts[core.AccountDelegated])
+	fmt.Println(i18n.T("report.inspect.contract", counts[core.AccountContract], safes))
+	if failed > 0 {
+		fmt.Println(i18n.T("report.inspect.failed", failed))
+	}
+	fmt.Println(i18n.T("report.inspect.time", time.Since(startTime)))
+	fmt.Printf("--------------------------------------------------\n")
+
+	if *outPath != "" {
+		rep := inspectReport{ChainID: client.ChainID.Int64(), Block: blockNumber, Profiles: profiles}
+		if err := report.WriteJSON(*outPath, rep); err != nil {
+			logger.Warn("failed to write report", "path", *outPath, "err", err)
+		} else {
+			logger.Info("report written", "path", *outPath)
+		}
+	}
+}
+
+// inspectAccounts 在指定区块上生成地址画像，节点不支持 JSON-RPC 批量时逐个请求
+func inspectAccounts(cfg Config, client *core.EvmClient, addresses []common.Address, block *big.Int) ([]core.AccountProfile, error) {
+	mc, err := newMultiChecker(cfg, client, block)
+	if err != nil {
+		return nil, err
+	}
+	batcher := client.Batcher(block)
+	batcher.Sequential = !core.SupportsRPCBatch(client.Client.Client())
+	return account.NewInspector(batcher, mc).Inspect(addresses)
+}
+
+func kindIcon(kind core.AccountKind) string {
+	switch kind {
+	case core.AccountDelegated:
+		return "🔗"
+	case core.AccountContract:
+		return "📜"
+	default:
+		return "👤"
+	}
+}
+
+// describeProfile 单行描述地址画像
+func describeProfile(p core.AccountProfile, symbol string) string {
+	s := string(p.Kind)
+	switch {
+	case p.Wallet == "safe":
+		s = fmt.Sprintf("safe %d/%d", p.Threshold, len(p.Owners))
+	case p.DelegatedTo != nil:
+		s += " → " + p.DelegatedTo.Hex()
+	}
+	s += fmt.Sprintf(" | nonce %d", p.Nonce)
+	if p.Kind != core.AccountContract && !p.HasSentTx {
+		s += " (never sent)"
+	}
+	if p.Balance != nil {
+		s += fmt.Sprintf(" | %.4f %s", p.Balance, symbol)
+	}
+	return s
+}
@@ -3,147 +3,185 @@ package main
 import (
 	"bufio"
 	"chain-lens/core"
-	"chain-lens/modules/erc20"
-	"chain-lens/modules/erc721"
+	"chain-lens/i18n"
+	"chain-lens/metrics"
 	"chain-lens/modules/multicall"
-	"chain-lens/modules/native"
+	"chain-lens/modules/pricing"
+	"chain-lens/notify"
+	"chain-lens/report"
+	"chain-lens/scanner"
+	"chain-lens/store"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
 	"math/big"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
 type Config struct {
-	RpcURL       string `json:"rpc_url"`
-	TokenAddress string `json:"token_address"`
-	TokenType    string `json:"token_type"`
-}
-
-type RetryTask struct {
-	Index   int
-	Address common.Address
+	RpcURL       string           `json:"rpc_url"`
+	RpcURLs      []string         `json:"rpc_urls,omitempty"` // 可选：serve 模式使用的 RPC 连接池
+	TokenAddress string           `json:"token_address"`
+	TokenType    string           `json:"token_type"`
+	DBPath       string           `json:"db_path,omitempty"`        // 可选：SQLite 结果库路径，为空则不落库
+	Output       string           `json:"output,omitempty"`         // 可选：JSON 快照输出路径，可用于 diff
+	Stats        bool             `json:"stats,omitempty"`          // 可选：输出持有人分布统计
+	TopN         int              `json:"top_n,omitempty"`          // 统计模式下展示的头部持有人数量
+	IPFSGateway  string           `json:"ipfs_gateway,omitempty"`   // 可选：解析 ipfs:// 元数据使用的网关
+	Prices       []pricing.Route  `json:"prices,omitempty"`         // 可选：美元报价路由 (chainlink / uniswap_v2 / uniswap_v3 / fixed)
+	Chains       []core.ChainInfo `json:"chains,omitempty"`         // 可选：覆盖内置链信息 (原生币符号、Multicall3 地址/部署区块)
+	Strategy     string           `json:"batch_strategy,omitempty"` // 可选：批量方式 auto / multicall / deployless / rpc-batch / single
+	Networks     []NetworkConfig  `json:"networks,omitempty"`       // 可选：多链模式，每条链单独的 RPC 池和资产列表
+	Inspect      bool             `json:"inspect,omitempty"`        // 可选：附带地址画像 (EOA / 合约 / EIP-7702 / Safe)
+	SafeAssets   []AssetConfig    `json:"safe_assets,omitempty"`    // 可选：safes 子命令查询的金库资产
+	Watch        WatchConfig      `json:"watch,omitempty"`          // 可选：watch 子命令的区块订阅、阈值和告警出口
+	Notify       *notify.Config   `json:"notify,omitempty"`         // 可选：运行汇总和 watch 事件的 webhook 通知 (签名、重试、死信)
+	MetricsFile  string           `json:"metrics_file,omitempty"`   // 可选：运行结束后把 Prometheus 指标写到这个文件 (node_exporter textfile)
 }
 
 func main() {
-	// 读取配置文件
-	configFile, err := os.ReadFile("config.json")
-	if err != nil {
-		log.Fatal("请确保目录下有 config.json 文件")
-	}
-
-	var cfg Config
-	if err := json.Unmarshal(configFile, &cfg); err != nil {
-		log.Fatal("配置解析失败，请检查 json 格式")
+	// 先按环境变量选择语言，解析参数后 cli.setup 再按 -lang 调整
+	i18n.SetLang(i18n.Detect(""))
+	// 子命令分发
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "history":
+			runHistory(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		case "holders":
+			runHolders(os.Args[2:])
+			return
+		case "nfts":
+			runNFTs(os.Args[2:])
+			return
+		case "owners":
+			runOwners(os.Args[2:])
+			return
+		case "allowances":
+			runAllowances(os.Args[2:])
+			return
+		case "inspect":
+			runInspect(os.Args[2:])
+			return
+		case "safes":
+			runSafes(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "grpc":
+			runGRPC(os.Args[2:])
+			return
+		case "watch":
+			runWatch(os.Args[2:])
+			return
+		}
 	}
 
 	filePath := flag.String("file", "wallets.txt", "包含钱包地址的文件路径 (每行一个)")
+	dbPath := flag.String("db", "", "SQLite 结果库路径 (默认取配置 db_path，为空则不记录运行历史)")
+	outPath := flag.String("out", "", "把结果写成 JSON 快照 (可用于 diff，默认取配置 output)")
+	stats := flag.Bool("stats", false, "输出持有人分布统计 (Top N、基尼系数、分位数、直方图)")
+	topN := flag.Int("top", 0, "统计模式下展示的头部持有人数量 (默认取配置 top_n)")
+	strategy := flag.String("strategy", "", "批量方式: auto / multicall / deployless / rpc-batch / single (默认取配置 batch_strategy)")
+	inspect := flag.Bool("inspect", false, "附带地址画像 (EOA / 合约 / EIP-7702 / Safe)")
+	metricsFile := flag.String("metrics-file", "", "运行结束后把 Prometheus 指标写到这个文件 (默认取配置 metrics_file)")
+	cli := addCommonFlags(flag.CommandLine)
 	flag.Parse()
+	cli.setup()
+
+	// 先按 -lang 设置语言再读取配置文件，配置错误的提示也用所选语言
+	cfg := loadConfig("config.json")
+	// 命令行显式指定的参数覆盖配置
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "db":
+			cfg.DBPath = *dbPath
+		case "out":
+			cfg.Output = *outPath
+		case "stats":
+			cfg.Stats = *stats
+		case "top":
+			cfg.TopN = *topN
+		case "strategy":
+			cfg.Strategy = *strategy
+		case "inspect":
+			cfg.Inspect = *inspect
+		case "metrics-file":
+			cfg.MetricsFile = *metricsFile
+		}
+	})
+	// 通知配置有问题时在扫描前就退出，而不是跑完才发现发不出去
+	if cfg.Notify != nil {
+		if err := cfg.Notify.Validate(); err != nil {
+			fatal(i18n.Errorf("err.notify_config", err))
+		}
+	}
 
 	// 读取文件
 	addresses, err := loadAddresses(*filePath)
 	if err != nil {
-		log.Fatalf("❌ 无法读取文件: %v", err)
+		fatal(i18n.Errorf("err.read_file", err))
 	}
 
+	// 配置了 networks 时走多链模式
+	if len(cfg.Networks) > 0 {
+		RunMultiChain(cfg, addresses)
+		return
+	}
 	RunApp(cfg, addresses)
 }
 
 func RunApp(cfg Config, addresses []common.Address) {
-	fmt.Printf("📂 Successfully loaded %d wallet addresses\n", len(addresses))
-
-	// 连接RPC节点
-	client, err := core.NewClient(cfg.RpcURL)
+	logger.Info("wallet addresses loaded", "count", len(addresses))
 
+	// 连接RPC节点 (配置了 rpc_urls 时批次分摊到连接池的各个节点)
+	pool := connectPool(cfg)
+	defer pool.Close()
+	client := pool.Next()
+	logger.Info("connected", "chain", client.Chain.Name, "chain_id", client.Chain.ChainID, "rpc", len(pool.Clients))
+	startTime := time.Now()
+	// 固定本次运行的区块高度，保证落库的数据和区块对应
+	blockNumber, err := client.Client.BlockNumber(context.Background())
 	if err != nil {
-		log.Fatal(err)
+		fatal(i18n.Errorf("err.block_number", err))
 	}
-	defer client.Close()
-	fmt.Println("Connected to EVM")
-	startTime := time.Now()
-	multicallChecker, _ := multicall.NewMultiChecker(client.Client)
-	// 检查配置文件token_type
-	tokenType, err := ParseTokenType(cfg.TokenType)
+	block := new(big.Int).SetUint64(blockNumber)
+	s, err := newScanner(cfg, pool, block)
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
-	tokenBalances, err := multicallChecker.CheckToken(tokenType, common.HexToAddress(cfg.TokenAddress), addresses)
-
-	// 准备重试任务列表
-	var retryTasks []RetryTask
-
+	s, finish := withProgress(s)
+	results, err := s.Scan(context.Background(), addresses)
+	finish()
 	if err != nil {
-		// --- 情况 A: Multicall 整体失败 (比如 RPC 不支持，或者合约报错) ---
-		fmt.Printf("⚠️ Multicall 整体失败: %v，切换全量并发查询模式...\n", err)
-		tokenBalances = make([]core.TokenBalance, len(addresses))
-		// 所有地址都要重试
-		for i, addr := range addresses {
-			retryTasks = append(retryTasks, RetryTask{Index: i, Address: addr})
-		}
-	} else {
-		// --- 情况 B: Multicall 成功，但可能有部分个例失败 ---
-		for i, tb := range tokenBalances {
-			if !tb.Success {
-				retryTasks = append(retryTasks, RetryTask{Index: i, Address: tb.Owner})
-			}
+		fatal(err)
+	}
+	tokenBalances := results[0].Balances
+
+	// 可选：地址画像
+	var profiles []core.AccountProfile
+	if cfg.Inspect {
+		profiles, err = inspectAccounts(cfg, client, addresses, block)
+		if err != nil {
+			logger.Warn("account inspection failed", "err", err)
 		}
 	}
-	// 执行并发补救 (如果有失败任务)
-	if len(retryTasks) > 0 {
-		fmt.Printf("🔄 开始并发修补 %d 个失败任务...\n", len(retryTasks))
-
-		var wg sync.WaitGroup
-		var mu sync.Mutex // 关键：保护 tokenBalances 的写锁
-
-		// 信号量：限制并发数 (比如限制 20 个并发)，防止把 RPC 节点打挂
-		sem := make(chan struct{}, 20)
-
-		// 初始化单次查询器 (Fallback Checker)
-		singleChecker := NewTokenChecker(cfg, client)
-
-		for _, task := range retryTasks {
-			wg.Add(1)
-			sem <- struct{}{} // 拿令牌
 
-			go func(t RetryTask) {
-				defer wg.Done()
-				defer func() { <-sem }() // 还令牌
-
-				// 执行单次查询
-				singleResult, err := singleChecker.BalanceOf(t.Address)
-
-				// 加锁回写数据
-				mu.Lock()
-				defer mu.Unlock()
-
-				if err != nil {
-					// 彻底失败：记录错误
-					fmt.Printf("❌ 重试仍失败 [%d] %s: %v\n", t.Index, t.Address.Hex(), err)
-					// 确保结果数组里对应的位置有标记
-					tokenBalances[t.Index].Owner = t.Address
-					tokenBalances[t.Index].Success = false
-				} else {
-					// 🎉 挽救成功：更新原本的数据
-					fmt.Printf("✅ 修补成功 [%d] %s\n", t.Index, t.Address.Hex())
-					// 这里要把 singleResult 转换成 TokenBalance 格式填回去
-					tokenBalances[t.Index] = core.TokenBalance{
-						TokenAddress: common.HexToAddress(cfg.TokenAddress),
-						Owner:        t.Address,
-						Balance:      singleResult.Balance, // 假设 singleResult 结构
-						Symbol:       singleResult.Symbol,
-						Success:      true, // 标记为成功
-					}
-				}
-			}(task)
-		}
-		wg.Wait()
+	// 可选：按报价路由换算美元价值
+	var quote *pricing.Quote
+	if len(cfg.Prices) > 0 {
+		quote = fetchPrice(cfg, client, block)
 	}
+
 	// 最终统计
 	//idexList := make([]int, 0, 100)
 	totalBalance := new(big.Float)
@@ -161,55 +199,201 @@ func RunApp(cfg Config, addresses []common.Address) {
 			//	idexList = append(idexList, idx+1)
 			//}
 			// 这里可以打印最终结果
-			fmt.Printf("✅ [%d] Address: %s... | Balance: %s %s \n", idx+1, tb.Owner.String()[:6], fmt.Sprintf("%.4f", tb.Balance), tb.Symbol)
+			line := i18n.T("progress.balance", idx+1, tb.Owner.String()[:6], fmt.Sprintf("%.4f", tb.Balance), tb.Symbol)
+			if value := usdValue(tb.Balance, quote); value != nil {
+				line += fmt.Sprintf(" | $%.2f", value)
+			}
+			if idx < len(profiles) && profiles[idx].Success {
+				line += " | " + kindIcon(profiles[idx].Kind) + " " + string(profiles[idx].Kind)
+				if profiles[idx].Wallet != "" {
+					line += " (" + profiles[idx].Wallet + ")"
+				}
+			}
+			fmt.Println(line)
+		}
+	}
+	summary := report.Summary{
+		ChainID:      client.ChainID.Int64(),
+		Network:      client.Chain.Name,
+		Block:        blockNumber,
+		TokenAddress: common.HexToAddress(cfg.TokenAddress),
+		Symbol:       tokenBalances[0].Symbol,
+		WalletCount:  len(addresses),
+		SuccessCount: successCount,
+		TotalBalance: totalBalance,
+		TotalUSD:     usdValue(totalBalance, quote),
+		StartedAt:    startTime,
+		Duration:     time.Since(startTime),
+	}
+	printSummary(summary)
+
+	var stats *report.Stats
+	if cfg.Stats {
+		stats = buildStats(cfg, client, block, tokenBalances)
+		printStats(stats, tokenBalances[0].Symbol)
+	}
+
+	if cfg.DBPath != "" {
+		run := store.Run{
+			ChainID:      summary.ChainID,
+			Block:        summary.Block,
+			TokenAddress: summary.TokenAddress,
+			Symbol:       summary.Symbol,
+			WalletCount:  summary.WalletCount,
+			SuccessCount: summary.SuccessCount,
+			StartedAt:    summary.StartedAt,
+			Duration:     summary.Duration,
+		}
+		if err := saveRun(cfg, run, tokenBalances); err != nil {
+			logger.Warn("failed to save run", "db", cfg.DBPath, "err", err)
+		}
+	}
+	if cfg.Output != "" {
+		snap := report.NewSnapshot(client.ChainID.Int64(), blockNumber, common.HexToAddress(cfg.TokenAddress), tokenBalances)
+		snap.Stats = stats
+		if profiles != nil {
+			snap.AttachProfiles(profiles)
+		}
+		if quote != nil {
+			snap.ApplyPrice(quote.USD)
+		}
+		if err := report.WriteJSON(cfg.Output, snap); err != nil {
+			logger.Warn("failed to write snapshot", "path", cfg.Output, "err", err)
+		} else {
+			logger.Info("snapshot written", "path", cfg.Output)
+		}
+	}
+
+	if cfg.MetricsFile != "" {
+		// token 标签统一用校验和格式，和 watch 的 /metrics 一致
+		token := common.HexToAddress(cfg.TokenAddress).Hex()
+		for _, tb := range tokenBalances {
+			if tb.Success {
+				metrics.SetBalance(summary.ChainID, token, tb.Symbol, tb.Owner.Hex(), tb.Balance)
+			}
+		}
+		if err := metrics.WriteTextFile(cfg.MetricsFile); err != nil {
+			logger.Warn("failed to write metrics file", "path", cfg.MetricsFile, "err", err)
+		} else {
+			logger.Info("metrics written", "path", cfg.MetricsFile)
+		}
+	}
+	if cfg.Notify != nil && len(cfg.Notify.Webhooks) > 0 {
+		notifier, err := notify.New(*cfg.Notify)
+		if err == nil {
+			err = notifier.Send(notify.EventRunSummary, summary)
+		}
+		if err != nil {
+			logger.Warn("failed to send run summary", "err", err)
+		} else {
+			logger.Info("run summary sent", "webhooks", len(cfg.Notify.Webhooks))
 		}
 	}
+
+	//for _, v := range idexList {
+	//	fmt.Printf("%d ", v)
+	//}
+}
+
+// printSummary 打印运行结束时的汇总
+func printSummary(s report.Summary) {
 	fmt.Printf("\n--------------------------------------------------\n")
-	fmt.Printf("📊 Summary Report\n")
+	fmt.Println(i18n.T("report.summary.title"))
 	fmt.Printf("--------------------------------------------------\n")
-	fmt.Printf("✅ Success Rate : %d / %d\n", successCount, len(addresses))
+	fmt.Println(i18n.T("report.success_rate", s.SuccessCount, s.WalletCount))
 
 	// 格式化输出:
 	// %.4f 表示保留 4 位小数
 	// big.Float 实现了 fmt.Formatter 接口，可以直接这样打印
-	fmt.Printf("💰 Total Balance: %.4f %s\n ", totalBalance, tokenBalances[0].Symbol)
-	fmt.Printf("🎉 All tasks completed! Success: %d/%d | Time: %v\n", successCount, len(addresses), time.Since(startTime))
+	fmt.Println(i18n.T("report.summary.total", s.TotalBalance, s.Symbol))
+	if s.TotalUSD != nil {
+		fmt.Println(i18n.T("report.summary.total_value", s.TotalUSD))
+	}
+	fmt.Println(i18n.T("report.summary.done", s.SuccessCount, s.WalletCount, s.Duration))
 	fmt.Printf("--------------------------------------------------\n")
+}
 
-	//for _, v := range idexList {
-	//	fmt.Printf("%d ", v)
-	//}
+// collectBalances 在指定区块 (nil 表示 latest) 查询所有地址的余额。
+// 先走 Multicall 批量查询，整体或部分失败时再并发单查补救 (见 scanner 包)。
+func collectBalances(cfg Config, client *core.EvmClient, addresses []common.Address, block *big.Int) []core.TokenBalance {
+	s, err := newScanner(cfg, core.NewPoolOf(client), block)
+	if err != nil {
+		fatal(err)
+	}
+	s, finish := withProgress(s)
+	results, err := s.Scan(context.Background(), addresses)
+	finish()
+	if err != nil {
+		fatal(err)
+	}
+	return results[0].Balances
 }
 
-// NewTokenChecker creates a token checker.
-// Uses cfg.TokenType if set; otherwise auto-detects ERC20 → ERC721 → native.
-// Program exits if all attempts fail.
-func NewTokenChecker(cfg Config, evmClient *core.EvmClient) core.AssetChecker {
-	tokenAddr := common.HexToAddress(cfg.TokenAddress)
-	var checker core.AssetChecker
-	var err error
-	// 自动识别 ERC20 → ERC721 → native
-	checker, err = erc20.NewChecker(tokenAddr, evmClient)
-	if err == nil {
-		fmt.Println("🔹 Auto-detect ERC20 token")
-		return checker
+// newScanner 按配置的代币、批量方式创建 Scanner
+func newScanner(cfg Config, pool *core.ClientPool, block *big.Int) (*scanner.Scanner, error) {
+	asset, err := configAsset(cfg)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := multicall.ParseStrategy(cfg.Strategy)
+	if err != nil {
+		return nil, err
 	}
+	return scanner.New(scanner.Options{
+		Pool:     pool,
+		Assets:   []scanner.Asset{asset},
+		Block:    block,
+		Strategy: strategy,
+	})
+}
 
-	checker, err = erc721.NewChecker(tokenAddr, evmClient)
-	if err == nil {
-		fmt.Println("🔹 Auto-detect ERC721 token")
-		return checker
+// configAsset 把配置里的 token_type / token_address 转成 scanner.Asset
+func configAsset(cfg Config) (scanner.Asset, error) {
+	tokenType, err := ParseTokenType(cfg.TokenType)
+	if err != nil {
+		return scanner.Asset{}, err
+	}
+	return scanner.Asset{Type: tokenType, Address: common.HexToAddress(cfg.TokenAddress)}, nil
+}
+
+// connect 连接 RPC 节点，并用配置里的 chains 覆盖内置的链信息
+func connect(cfg Config) (*core.EvmClient, error) {
+	client, err := core.NewClientWithLogger(cfg.RpcURL, logger)
+	if err != nil {
+		return nil, err
+	}
+	client.Chain = core.LookupChain(client.ChainID, cfg.Chains)
+	return client, nil
+}
+
+// newMultiChecker 按配置的批量方式创建固定在 block 上的 MultiChecker
+func newMultiChecker(cfg Config, client *core.EvmClient, block *big.Int) (*multicall.MultiChecker, error) {
+	strategy, err := multicall.ParseStrategy(cfg.Strategy)
+	if err != nil {
+		return nil, err
 	}
+	mc, err := multicall.NewMultiCheckerForChain(client.Client, client.Chain)
+	if err != nil {
+		return nil, err
+	}
+	mc.BlockNumber = block
+	mc.Strategy = strategy
+	mc.Logger = client.Log()
+	return mc, nil
+}
 
-	checker, err = native.NewChecker(evmClient)
-	if err == nil {
-		fmt.Println("🔹 Auto-detect native token")
-		return checker
+// loadConfig 读取并解析配置文件，失败直接退出
+func loadConfig(path string) Config {
+	configFile, err := os.ReadFile(path)
+	if err != nil {
+		fatal(i18n.Errorf("err.config_not_found", path))
 	}
 
-	// 所有方式都失败
-	log.Fatalf("❌ Failed to create checker for token: %s", cfg.TokenAddress)
-	return nil
+	var cfg Config
+	if err := json.Unmarshal(configFile, &cfg); err != nil {
+		fatal(i18n.Errorf("err.config_invalid", path, err))
+	}
+	return cfg
 }
 
 func loadAddresses(path string) ([]common.Address, error) {
@@ -230,7 +414,7 @@ func loadAddresses(path string) ([]common.Address, error) {
 		}
 		// 校验是否为合法地址
 		if !common.IsHexAddress(line) {
-			log.Printf("⚠️ 跳过无效地址: %s", line)
+			logger.Warn("skipping invalid address", "line", line)
 			continue
 		}
 		addresses = append(addresses, common.HexToAddress(line))
@@ -244,7 +428,7 @@ func loadAddresses(path string) ([]common.Address, error) {
 
 func ParseTokenType(s string) (multicall.TokenType, error) {
 	if s == "" {
-		return 0, fmt.Errorf("❌ Configuration Error: 'token_type' in config file cannot be empty. Valid values are: native, erc20, erc721")
+		return 0, i18n.Errorf("err.token_type_empty")
 	}
 	switch strings.ToLower(s) {
 	case "native":
@@ -254,21 +438,6 @@ func ParseTokenType(s string) (multicall.TokenType, error) {
 	case "erc721":
 		return multicall.TokenTypeERC721, nil
 	default:
-		return 0, fmt.Errorf("❌ Configuration Error: Invalid token_type. Valid values are: native, erc20, erc721")
-	}
-}
-
-// ChunkSlice 把一个大的钱包地址列表，切分成多个小批次
-// 例如：输入 5 个地址，batchSize 是 2 -> 输出 [[1,2], [3,4], [5]]
-func ChunkSlice(slice []string, chunkSize int) [][]string {
-	var chunks [][]string
-	for i := 0; i < len(slice); i += chunkSize {
-		end := i + chunkSize
-		// 防止越界
-		if end > len(slice) {
-			end = len(slice)
-		}
-		chunks = append(chunks, slice[i:end])
+		return 0, i18n.Errorf("err.token_type_invalid", s)
 	}
-	return chunks
 }
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "chainlens"
+
+// Registry chain-lens 自己的指标注册表 (不用全局默认注册表，避免和嵌入方的指标混在一起)
+var Registry = prometheus.NewRegistry()
+
+var (
+	// RPCDuration 每个 RPC 端点、每个方法的请求耗时
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "JSON-RPC request latency by endpoint and method.",
+		Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"endpoint", "method"})
+
+	// RPCErrors 网络错误或非 2xx 的 RPC 请求数
+	RPCErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_errors_total",
+		Help:      "JSON-RPC requests that failed at the HTTP level.",
+	}, []string{"endpoint", "method"})
+
+	// BatchSize 每次批量调用打包的子调用数
+	BatchSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "batch_calls",
+		Help:      "Number of sub-calls per batched call.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 7), // 1 .. 4096
+	}, []string{"strategy"})
+
+	// SubcallFailures 批量调用里失败的子调用数
+	SubcallFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "subcall_failures_total",
+		Help:      "Sub-calls that reverted or returned no data inside a batched call.",
+	}, []string{"strategy"})
+
+	// FallbackRetries 批量查询失败后逐个补查的次数，按结果区分
+	FallbackRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fallback_retries_total",
+		Help:      "Single-call retries after a failed batch, by result.",
+	}, []string{"result"})
+
+	// WalletBalance 跟踪钱包的最新余额 (代币单位)
+	WalletBalance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "wallet_balance",
+		Help:      "Latest known balance of a tracked wallet, in token units.",
+	}, []string{"chain_id", "token", "symbol", "owner"})
+)
+
+func init() {
+	Registry.MustRegister(
+		RPCDuration, RPCErrors, BatchSize, SubcallFailures, FallbackRetries, WalletBalance,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler /metrics 的 HTTP 处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveBatch 记录一次批量调用的大小和其中失败的子调用数
+func ObserveBatch(strategy string, size, failed int) {
+	BatchSize.WithLabelValues(strategy).Observe(float64(size))
+	if failed > 0 {
+		SubcallFailures.WithLabelValues(strategy).Add(float64(failed))
+	}
+}
+
+// ObserveRetry 记录一次补查结果
+func ObserveRetry(ok bool) {
+	result := "success"
+	if !ok {
+		result = "failure"
+	}
+	FallbackRetries.WithLabelValues(result).Inc()
+}
+
+// SetBalance 更新一个钱包的余额指标
+func SetBalance(chainID int64, token, symbol, owner string, balance *big.Float) {
+	if balance == nil {
+		return
+	}
+	f, _ := balance.Float64()
+	WalletBalance.WithLabelValues(strconv.FormatInt(chainID, 10), token, symbol, owner).Set(f)
+}
+
+// WriteTextFile 把当前指标写成文本格式，供 node_exporter 的 textfile collector 采集
+func WriteTextFile(path string) error {
+	return prometheus.WriteToTextfile(path, Registry)
+}
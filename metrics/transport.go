@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Transport 给 JSON-RPC 的 HTTP 请求计时，按端点和方法记录到 RPCDuration
+type Transport struct {
+	Base     http.RoundTripper
+	Endpoint string // 指标里的端点标签，只保留 scheme + host，避免把 URL 里的 API key 暴露出去
+}
+
+func NewTransport(rawURL string, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base, Endpoint: EndpointLabel(rawURL)}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := "unknown"
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			method = rpcMethod(data)
+		}
+	}
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	RPCDuration.WithLabelValues(t.Endpoint, method).Observe(time.Since(start).Seconds())
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		RPCErrors.WithLabelValues(t.Endpoint, method).Inc()
+	}
+	return resp, err
+}
+
+// rpcMethod 从请求体里取出 JSON-RPC 方法名。批量请求里方法都相同时记为 batch/<method>，否则记为 batch。
+func rpcMethod(body []byte) string {
+	type call struct {
+		Method string `json:"method"`
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var calls []call
+		if json.Unmarshal(body, &calls) != nil || len(calls) == 0 {
+			return "batch"
+		}
+		for _, c := range calls[1:] {
+			if c.Method != calls[0].Method {
+				return "batch"
+			}
+		}
+		return "batch/" + calls[0].Method
+	}
+	var c call
+	if json.Unmarshal(body, &c) != nil || c.Method == "" {
+		return "unknown"
+	}
+	return c.Method
+}
+
+// EndpointLabel 把 RPC 地址化简成 scheme://host
+func EndpointLabel(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRPCMethod(t *testing.T) {
+	cases := map[string]string{
+		`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`:        "eth_call",
+		`[{"method":"eth_getBalance"},{"method":"eth_getBalance"}]`:       "batch/eth_getBalance",
+		`[{"method":"eth_getCode"},{"method":"eth_getTransactionCount"}]`: "batch",
+		`not json`: "unknown",
+	}
+	for body, want := range cases {
+		if got := rpcMethod([]byte(body)); got != want {
+			t.Errorf("rpcMethod(%s) = %s, want %s", body, got, want)
+		}
+	}
+	if got := EndpointLabel("https://mainnet.infura.io/v3/secret-key"); got != "https://mainnet.infura.io" {
+		t.Errorf("endpoint label must drop the path: %s", got)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(srv.URL+"/key", nil)}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"method":"eth_chainId"}`))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if n := testutil.CollectAndCount(RPCDuration, namespace+"_rpc_request_duration_seconds"); n != 1 {
+		t.Fatalf("expected one observed series, got %d", n)
+	}
+	if got := testutil.ToFloat64(RPCErrors.WithLabelValues(EndpointLabel(srv.URL), "eth_chainId")); got != 0 {
+		t.Fatalf("successful request must not count as error, got %v", got)
+	}
+}
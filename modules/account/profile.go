@@ -0,0 +1,126 @@
+package account
+
+import (
+	"bytes"
+	"chain-lens/core"
+	"chain-lens/modules/multicall"
+	"chain-lens/modules/safe"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DelegationPrefix EIP-7702 委托账户的代码前缀，后面紧跟 20 字节的委托目标地址
+var DelegationPrefix = []byte{0xef, 0x01, 0x00}
+
+// Classify 根据地址上的代码判断账户类型，EIP-7702 账户同时返回委托目标
+func Classify(code []byte) (core.AccountKind, *common.Address) {
+	if len(code) == 0 {
+		return core.AccountEOA, nil
+	}
+	if len(code) == len(DelegationPrefix)+common.AddressLength && bytes.HasPrefix(code, DelegationPrefix) {
+		target := common.BytesToAddress(code[len(DelegationPrefix):])
+		return core.AccountDelegated, &target
+	}
+	return core.AccountContract, nil
+}
+
+// AccountBatcher 批量查询 nonce 和代码，通常是 *core.RPCBatcher
+type AccountBatcher interface {
+	Nonces(addrs []common.Address) ([]int64, error)
+	Codes(addrs []common.Address) ([][]byte, error)
+}
+
+// BalanceChecker 批量查询原生币余额，并执行 Safe 检测的只读调用，通常是 *multicall.MultiChecker
+type BalanceChecker interface {
+	core.BatchCaller
+	CheckToken(tType multicall.TokenType, tokenAddr common.Address, owners []common.Address) ([]core.TokenBalance, error)
+}
+
+// Inspector 批量生成地址画像：
+// nonce 和代码走 JSON-RPC 批量请求，原生币余额和 Safe 检测走 MultiChecker，都按 BatchSize 分批。
+type Inspector struct {
+	Batcher   AccountBatcher
+	Checker   BalanceChecker
+	BatchSize int
+}
+
+func NewInspector(batcher AccountBatcher, checker BalanceChecker) *Inspector {
+	return &Inspector{Batcher: batcher, Checker: checker, BatchSize: core.DefaultBatchSize}
+}
+
+// Inspect 返回每个地址的画像，顺序与 addrs 一致
+func (in *Inspector) Inspect(addrs []common.Address) ([]core.AccountProfile, error) {
+	nonces, err := in.Batcher.Nonces(addrs)
+	if err != nil {
+		return nil, fmt.Errorf("fetch nonces: %w", err)
+	}
+	codes, err := in.Batcher.Codes(addrs)
+	if err != nil {
+		return nil, fmt.Errorf("fetch code: %w", err)
+	}
+	balances, err := in.balances(addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]core.AccountProfile, len(addrs))
+	var candidates []int // 可能是合约钱包的地址下标
+	for i, addr := range addrs {
+		p := &profiles[i]
+		p.Address = addr
+		if i < len(balances) && balances[i].Success {
+			p.Balance = balances[i].Balance
+		}
+		// nonce 或代码查询失败都视为画像不完整
+		if nonces[i] < 0 || codes[i] == nil {
+			continue
+		}
+		p.Success = true
+		p.Nonce = uint64(nonces[i])
+		p.CodeSize = len(codes[i])
+		p.Kind, p.DelegatedTo = Classify(codes[i])
+		if p.Kind != core.AccountContract {
+			p.HasSentTx = p.Nonce > 0
+		}
+		if p.Kind != core.AccountEOA {
+			candidates = append(candidates, i)
+		}
+	}
+
+	if len(candidates) > 0 {
+		targets := make([]common.Address, len(candidates))
+		for j, i := range candidates {
+			targets[j] = addrs[i]
+		}
+		infos, err := safe.Detect(in.Checker, targets, in.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range candidates {
+			if infos[j].IsSafe {
+				profiles[i].Wallet = "safe"
+				profiles[i].Owners = infos[j].Owners
+				profiles[i].Threshold = infos[j].Threshold
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// balances 分批查询原生币余额，单个批次太大时会超过节点的 gas / 请求大小限制
+func (in *Inspector) balances(addrs []common.Address) ([]core.TokenBalance, error) {
+	size := in.BatchSize
+	if size <= 0 {
+		size = core.DefaultBatchSize
+	}
+	balances := make([]core.TokenBalance, 0, len(addrs))
+	for i := 0; i < len(addrs); i += size {
+		chunk, err := in.Checker.CheckToken(multicall.TokenTypeNative, common.Address{}, addrs[i:min(i+size, len(addrs))])
+		if err != nil {
+			return nil, fmt.Errorf("fetch balances: %w", err)
+		}
+		balances = append(balances, chunk...)
+	}
+	return balances, nil
+}
@@ -0,0 +1,156 @@
+package account
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/multicall"
+	"chain-lens/modules/safe"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestClassify(t *testing.T) {
+	target := common.HexToAddress("0x63c0c19a282a1b52b07dd5a65b58948a07dae32b")
+	delegation := append(append([]byte{}, DelegationPrefix...), target.Bytes()...)
+
+	cases := []struct {
+		code []byte
+		kind core.AccountKind
+	}{
+		{nil, core.AccountEOA},
+		{[]byte{}, core.AccountEOA},
+		{delegation, core.AccountDelegated},
+		{common.FromHex("0x608060405260043610"), core.AccountContract},
+		// 前缀相同但长度不对的不是委托
+		{append(delegation, 0x00), core.AccountContract},
+	}
+	for _, c := range cases {
+		kind, delegatedTo := Classify(c.code)
+		if kind != c.kind {
+			t.Fatalf("code %x: want %s, got %s", c.code, c.kind, kind)
+		}
+		if kind == core.AccountDelegated && (delegatedTo == nil || *delegatedTo != target) {
+			t.Fatalf("unexpected delegation target: %v", delegatedTo)
+		}
+	}
+}
+
+// fakeChain 按地址返回预设的 nonce / 代码 / 余额，并模拟一个 1-of-1 的 Safe
+type fakeChain struct {
+	nonces     map[common.Address]int64
+	codes      map[common.Address][]byte
+	safe       common.Address
+	owner      common.Address
+	batchSize  int // 余额批次的最大长度
+	failNonces bool
+}
+
+func (f *fakeChain) Nonces(addrs []common.Address) ([]int64, error) {
+	if f.failNonces {
+		return nil, errors.New("rpc batch failed")
+	}
+	out := make([]int64, len(addrs))
+	for i, a := range addrs {
+		out[i] = f.nonces[a]
+	}
+	return out, nil
+}
+
+func (f *fakeChain) Codes(addrs []common.Address) ([][]byte, error) {
+	out := make([][]byte, len(addrs))
+	for i, a := range addrs {
+		out[i] = append([]byte{}, f.codes[a]...)
+	}
+	return out, nil
+}
+
+func (f *fakeChain) CheckToken(tType multicall.TokenType, token common.Address, owners []common.Address) ([]core.TokenBalance, error) {
+	f.batchSize = max(f.batchSize, len(owners))
+	out := make([]core.TokenBalance, len(owners))
+	for i, o := range owners {
+		out[i] = core.TokenBalance{Owner: o, Balance: big.NewFloat(float64(o[19])), Success: true}
+	}
+	return out, nil
+}
+
+func (f *fakeChain) Aggregate(calls []core.Call) ([]core.CallResult, error) {
+	parsed, _ := safe.SafeMetaData.GetAbi()
+	results := make([]core.CallResult, len(calls))
+	for i, c := range calls {
+		if c.Target != f.safe {
+			continue
+		}
+		switch string(c.Data[:4]) {
+		case string(parsed.Methods["getOwners"].ID):
+			out, _ := parsed.Methods["getOwners"].Outputs.Pack([]common.Address{f.owner})
+			results[i] = core.CallResult{Success: true, ReturnData: out}
+		case string(parsed.Methods["getThreshold"].ID):
+			results[i] = core.CallResult{Success: true, ReturnData: common.LeftPadBytes([]byte{1}, 32)}
+		}
+	}
+	return results, nil
+}
+
+func TestInspect(t *testing.T) {
+	var (
+		eoa       = common.HexToAddress("0x01")
+		fresh     = common.HexToAddress("0x02")
+		contract  = common.HexToAddress("0x03")
+		safeAddr  = common.HexToAddress("0x04")
+		delegated = common.HexToAddress("0x05")
+		target    = common.HexToAddress("0x63c0c19a282a1b52b07dd5a65b58948a07dae32b")
+	)
+	f := &fakeChain{
+		nonces: map[common.Address]int64{eoa: 3, contract: 1, delegated: 9},
+		codes: map[common.Address][]byte{
+			contract:  common.FromHex("0x6080"),
+			safeAddr:  common.FromHex("0x6080"),
+			delegated: append(append([]byte{}, DelegationPrefix...), target.Bytes()...),
+		},
+		safe:  safeAddr,
+		owner: eoa,
+	}
+	in := NewInspector(f, f)
+	in.BatchSize = 2 // 余额必须分批查询
+	addrs := []common.Address{eoa, fresh, contract, safeAddr, delegated}
+	profiles, err := in.Inspect(addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.batchSize > 2 {
+		t.Errorf("balances queried in a batch of %d, want at most 2", f.batchSize)
+	}
+
+	want := []struct {
+		kind   core.AccountKind
+		sent   bool
+		wallet string
+	}{
+		{core.AccountEOA, true, ""},
+		{core.AccountEOA, false, ""},
+		{core.AccountContract, false, ""},
+		{core.AccountContract, false, "safe"},
+		{core.AccountDelegated, true, ""},
+	}
+	for i, p := range profiles {
+		if !p.Success || p.Address != addrs[i] || p.Kind != want[i].kind || p.HasSentTx != want[i].sent || p.Wallet != want[i].wallet {
+			t.Errorf("profile %d: %+v", i, p)
+		}
+		if v, _ := p.Balance.Float64(); v != float64(i+1) {
+			t.Errorf("profile %d balance = %v, want %d", i, v, i+1)
+		}
+	}
+	if p := profiles[3]; p.Threshold != 1 || len(p.Owners) != 1 || p.Owners[0] != eoa {
+		t.Errorf("safe owners: %+v", p)
+	}
+	if p := profiles[4]; p.DelegatedTo == nil || *p.DelegatedTo != target {
+		t.Errorf("delegation target: %v", p.DelegatedTo)
+	}
+
+	f.failNonces = true
+	if _, err := in.Inspect(addrs); err == nil {
+		t.Fatal("nonce batch failure must be returned")
+	}
+}
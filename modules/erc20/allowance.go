@@ -0,0 +1,208 @@
+package erc20
+
+import (
+	"chain-lens/core"
+	"chain-lens/tools"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AllowanceMetaData ERC20 授权相关的函数和事件 (生成的 Token 绑定里没有)
+var AllowanceMetaData = &bind.MetaData{
+	ABI: `[{"constant":true,"inputs":[{"name":"_owner","type":"address"},{"name":"_spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"spender","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Approval","type":"event"}]`,
+}
+
+// UnlimitedThreshold 授权额度不小于 2^255 视为无限授权
+// (很多代币在 transferFrom 时会从 MaxUint256 往下扣，所以不要求严格等于 MaxUint256)
+var UnlimitedThreshold = new(big.Int).Lsh(big.NewInt(1), 255)
+
+// Allowance 某个钱包对某个 spender 在某个代币上的授权
+type Allowance struct {
+	Token     common.Address `json:"token"`
+	Symbol    string         `json:"symbol"`
+	Owner     common.Address `json:"owner"`
+	Spender   common.Address `json:"spender"`
+	Raw       *big.Int       `json:"raw"`
+	Amount    *big.Float     `json:"amount"` // 按 decimals 换算后的额度
+	Unlimited bool           `json:"unlimited"`
+	Success   bool           `json:"success"`
+	ValueUSD  *big.Float     `json:"value_usd,omitempty"` // 按报价换算的美元价值，没有报价时为 nil
+}
+
+// tokenInfo 代币的 decimals 和 symbol
+type tokenInfo struct {
+	decimals uint8
+	symbol   string
+}
+
+// Allowances 批量查询 tokens × owners × spenders 的 allowance(owner, spender)
+func Allowances(caller core.BatchCaller, tokens, owners, spenders []common.Address, batchSize int) ([]Allowance, error) {
+	infos, err := tokenInfos(caller, tokens)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := AllowanceMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	var calls []core.Call
+	var allowances []Allowance
+	for _, token := range tokens {
+		for _, owner := range owners {
+			for _, spender := range spenders {
+				data, err := parsed.Pack("allowance", owner, spender)
+				if err != nil {
+					return nil, fmt.Errorf("pack allowance: %w", err)
+				}
+				calls = append(calls, core.Call{Target: token, Data: data})
+				allowances = append(allowances, Allowance{
+					Token:   token,
+					Symbol:  infos[token].symbol,
+					Owner:   owner,
+					Spender: spender,
+					Raw:     new(big.Int),
+					Amount:  new(big.Float),
+				})
+			}
+		}
+	}
+
+	results, err := core.AggregateInBatches(caller, calls, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	for i, res := range results {
+		if !res.Success || len(res.ReturnData) < 32 {
+			continue
+		}
+		a := &allowances[i]
+		a.Success = true
+		a.Raw = new(big.Int).SetBytes(res.ReturnData[:32])
+		a.Amount = tools.WeiToEther(a.Raw, infos[a.Token].decimals)
+		a.Unlimited = a.Raw.Cmp(UnlimitedThreshold) >= 0
+	}
+	return allowances, nil
+}
+
+// SetPrices 按代币的美元单价 (token → USD) 计算每条授权的 ValueUSD，没有报价的代币保持 nil
+func SetPrices(allowances []Allowance, prices map[common.Address]*big.Float) {
+	for i := range allowances {
+		a := &allowances[i]
+		if price, ok := prices[a.Token]; ok && price != nil && a.Success {
+			a.ValueUSD = new(big.Float).Mul(a.Amount, price)
+		}
+	}
+}
+
+// SortByExposure 按风险排序：无限授权在前；有美元价值的按价值从大到小，排在没有报价的前面；
+// 没有报价时不同代币的额度不可比，按代币分组，组内按额度从大到小
+func SortByExposure(allowances []Allowance) {
+	sort.SliceStable(allowances, func(i, j int) bool {
+		a, b := allowances[i], allowances[j]
+		if a.Unlimited != b.Unlimited {
+			return a.Unlimited
+		}
+		if (a.ValueUSD != nil) != (b.ValueUSD != nil) {
+			return a.ValueUSD != nil
+		}
+		if a.ValueUSD != nil {
+			if c := a.ValueUSD.Cmp(b.ValueUSD); c != 0 {
+				return c > 0
+			}
+		}
+		if a.Token != b.Token {
+			return a.Token.Cmp(b.Token) < 0
+		}
+		return a.Amount.Cmp(b.Amount) > 0
+	})
+}
+
+// tokenInfos 一次 Multicall 查询所有代币的 decimals 和 symbol
+func tokenInfos(caller core.BatchCaller, tokens []common.Address) (map[common.Address]tokenInfo, error) {
+	parsed, err := TokenMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	decimalsData, _ := parsed.Pack("decimals")
+	symbolData, _ := parsed.Pack("symbol")
+	var calls []core.Call
+	for _, token := range tokens {
+		calls = append(calls,
+			core.Call{Target: token, Data: decimalsData},
+			core.Call{Target: token, Data: symbolData})
+	}
+	results, err := core.AggregateInBatches(caller, calls, 0)
+	if err != nil {
+		return nil, err
+	}
+	infos := make(map[common.Address]tokenInfo, len(tokens))
+	for i, token := range tokens {
+		info := tokenInfo{decimals: 18, symbol: "UNKNOWN"}
+		if res := results[2*i]; res.Success {
+			if out, err := parsed.Unpack("decimals", res.ReturnData); err == nil && len(out) > 0 {
+				info.decimals, _ = out[0].(uint8)
+			}
+		} else {
+			return nil, fmt.Errorf("failed to get decimals for token %s", token.Hex())
+		}
+		if res := results[2*i+1]; res.Success {
+			if out, err := parsed.Unpack("symbol", res.ReturnData); err == nil && len(out) > 0 {
+				info.symbol, _ = out[0].(string)
+			}
+		}
+		infos[token] = info
+	}
+	return infos, nil
+}
+
+// DiscoverSpenders 扫描 owners 发出的 Approval 事件，找出所有被授权过的 spender (按首次出现顺序)
+func DiscoverSpenders(evmClient *core.EvmClient, token common.Address, owners []common.Address, from, to, chunk uint64) ([]common.Address, error) {
+	parsed, err := AllowanceMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	approvalTopic := parsed.Events["Approval"].ID
+
+	seen := make(map[common.Address]bool)
+	var spenders []common.Address
+	const ownerTopicBatch = 100
+	for i := 0; i < len(owners); i += ownerTopicBatch {
+		var ownerTopics []common.Hash
+		for _, owner := range owners[i:min(i+ownerTopicBatch, len(owners))] {
+			ownerTopics = append(ownerTopics, common.BytesToHash(owner.Bytes()))
+		}
+		err := core.ScanBlockRange(from, to, chunk, func(start, end uint64) error {
+			logs, err := evmClient.Client.FilterLogs(context.Background(), ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(start),
+				ToBlock:   new(big.Int).SetUint64(end),
+				Addresses: []common.Address{token},
+				Topics:    [][]common.Hash{{approvalTopic}, ownerTopics},
+			})
+			if err != nil {
+				return err
+			}
+			for _, l := range logs {
+				if len(l.Topics) < 3 {
+					continue
+				}
+				spender := common.BytesToAddress(l.Topics[2].Bytes())
+				if !seen[spender] {
+					seen[spender] = true
+					spenders = append(spenders, spender)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return spenders, nil
+}
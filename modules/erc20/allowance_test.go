@@ -0,0 +1,222 @@
+package erc20
+
+import (
+	"bytes"
+	"chain-lens/core"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	usdc    = common.HexToAddress("0x0a")
+	shib    = common.HexToAddress("0x0b")
+	broken  = common.HexToAddress("0x0c") // decimals() revert
+	alice   = common.HexToAddress("0xa1")
+	router  = common.HexToAddress("0xee")
+	reverts = common.HexToAddress("0xff") // 对这个 spender 的 allowance() revert
+)
+
+var (
+	symbols = map[common.Address]string{usdc: "USDC", shib: "SHIB", broken: "BRK"}
+	names   = map[common.Address]string{alice: "alice", router: "router", reverts: "reverts"}
+)
+
+// fakeTokens 按 calldata 模拟几个 ERC20 合约
+type fakeTokens struct {
+	t          *testing.T
+	decimals   map[common.Address]uint8
+	allowances map[[2]common.Address]*big.Int // (token, spender) → 额度
+}
+
+func (f *fakeTokens) Aggregate(calls []core.Call) ([]core.CallResult, error) {
+	token, _ := TokenMetaData.GetAbi()
+	allowance, _ := AllowanceMetaData.GetAbi()
+	var results []core.CallResult
+	for _, c := range calls {
+		var (
+			out []byte
+			err error
+		)
+		switch {
+		case bytes.HasPrefix(c.Data, token.Methods["decimals"].ID):
+			dec, ok := f.decimals[c.Target]
+			if !ok {
+				results = append(results, core.CallResult{})
+				continue
+			}
+			out, err = token.Methods["decimals"].Outputs.Pack(dec)
+		case bytes.HasPrefix(c.Data, token.Methods["symbol"].ID):
+			out, err = token.Methods["symbol"].Outputs.Pack(symbols[c.Target])
+		case bytes.HasPrefix(c.Data, allowance.Methods["allowance"].ID):
+			args, _ := allowance.Methods["allowance"].Inputs.Unpack(c.Data[4:])
+			spender := args[1].(common.Address)
+			if spender == reverts {
+				results = append(results, core.CallResult{})
+				continue
+			}
+			amount, ok := f.allowances[[2]common.Address{c.Target, spender}]
+			if !ok {
+				amount = new(big.Int)
+			}
+			out, err = allowance.Methods["allowance"].Outputs.Pack(amount)
+		default:
+			results = append(results, core.CallResult{})
+			continue
+		}
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		results = append(results, core.CallResult{Success: true, ReturnData: out})
+	}
+	return results, nil
+}
+
+func newFakeTokens(t *testing.T) *fakeTokens {
+	return &fakeTokens{
+		t:        t,
+		decimals: map[common.Address]uint8{usdc: 6, shib: 18},
+		allowances: map[[2]common.Address]*big.Int{
+			{usdc, router}: big.NewInt(1000e6),                                   // 1000 USDC
+			{shib, router}: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)), // 1000 SHIB
+			{shib, alice}:  math.MaxBig256,                                       // 无限授权
+		},
+	}
+}
+
+func TestAllowances(t *testing.T) {
+	fake := newFakeTokens(t)
+	got, err := Allowances(fake, []common.Address{usdc, shib}, []common.Address{alice}, []common.Address{router, reverts, alice}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 6 {
+		t.Fatalf("expected 2 tokens × 1 owner × 3 spenders, got %d", len(got))
+	}
+	find := func(token, spender common.Address) Allowance {
+		for _, a := range got {
+			if a.Token == token && a.Spender == spender {
+				return a
+			}
+		}
+		t.Fatalf("missing allowance %s → %s", token.Hex(), spender.Hex())
+		return Allowance{}
+	}
+
+	if a := find(usdc, router); !a.Success || a.Unlimited || a.Amount.String() != "1000" || a.Symbol != "USDC" {
+		t.Errorf("usdc allowance: %+v", a)
+	}
+	if a := find(shib, alice); !a.Success || !a.Unlimited {
+		t.Errorf("unlimited allowance not detected: %+v", a)
+	}
+	if a := find(usdc, reverts); a.Success || a.Raw.Sign() != 0 {
+		t.Errorf("reverted allowance() must be unsuccessful: %+v", a)
+	}
+
+	// decimals 查不到时无法换算额度，直接报错
+	if _, err := Allowances(fake, []common.Address{usdc, broken}, []common.Address{alice}, []common.Address{router}, 0); err == nil {
+		t.Fatal("token without decimals must be an error")
+	}
+}
+
+func TestSortByExposure(t *testing.T) {
+	fake := newFakeTokens(t)
+	allowances, err := Allowances(fake, []common.Address{shib, usdc}, []common.Address{alice}, []common.Address{router, alice}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := func() []string {
+		var out []string
+		for _, a := range allowances {
+			out = append(out, a.Symbol+"→"+names[a.Spender])
+		}
+		return out
+	}
+
+	// 没有报价：无限授权在前，其余按代币分组 (1000 SHIB 和 1000 USDC 不比较大小)
+	SortByExposure(allowances)
+	if got, want := order(), []string{"SHIB→alice", "USDC→router", "USDC→alice", "SHIB→router"}; !slices.Equal(got, want) {
+		t.Errorf("unpriced order = %v, want %v", got, want)
+	}
+
+	// 有报价：按美元价值排序
+	SetPrices(allowances, map[common.Address]*big.Float{usdc: big.NewFloat(1), shib: big.NewFloat(0.00001)})
+	SortByExposure(allowances)
+	if got, want := order(), []string{"SHIB→alice", "USDC→router", "SHIB→router", "USDC→alice"}; !slices.Equal(got, want) {
+		t.Errorf("priced order = %v, want %v", got, want)
+	}
+	if v, _ := allowances[1].ValueUSD.Float64(); v != 1000 {
+		t.Errorf("usdc value = %v, want 1000", v)
+	}
+}
+
+// fakeApprovals 进程内的假节点，只实现 eth_getLogs
+type fakeApprovals struct {
+	logs []types.Log
+}
+
+type filterArg struct {
+	Address   []common.Address `json:"address"`
+	FromBlock hexutil.Uint64   `json:"fromBlock"`
+	ToBlock   hexutil.Uint64   `json:"toBlock"`
+	Topics    [][]common.Hash  `json:"topics"`
+}
+
+func (f *fakeApprovals) GetLogs(arg filterArg) ([]types.Log, error) {
+	var out []types.Log
+	for _, l := range f.logs {
+		if l.BlockNumber < uint64(arg.FromBlock) || l.BlockNumber > uint64(arg.ToBlock) || !slices.Contains(arg.Address, l.Address) {
+			continue
+		}
+		match := true
+		for i, want := range arg.Topics {
+			if len(want) > 0 && !slices.Contains(want, l.Topics[i]) {
+				match = false
+			}
+		}
+		if match {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func TestDiscoverSpenders(t *testing.T) {
+	parsed, _ := AllowanceMetaData.GetAbi()
+	approval := func(block uint64, owner, spender common.Address) types.Log {
+		return types.Log{
+			Address:     usdc,
+			Topics:      []common.Hash{parsed.Events["Approval"].ID, common.BytesToHash(owner.Bytes()), common.BytesToHash(spender.Bytes())},
+			Data:        common.BigToHash(big.NewInt(1)).Bytes(),
+			BlockNumber: block,
+		}
+	}
+	bob := common.HexToAddress("0xb0")
+	fake := &fakeApprovals{logs: []types.Log{
+		approval(5, alice, router),
+		approval(6, bob, reverts), // 不是要审计的钱包
+		approval(7, alice, reverts),
+		approval(8, alice, router), // 重复
+	}}
+	node := rpc.NewServer()
+	if err := node.RegisterName("eth", fake); err != nil {
+		t.Fatal(err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(node))
+	t.Cleanup(client.Close)
+
+	spenders, err := DiscoverSpenders(&core.EvmClient{Client: client}, usdc, []common.Address{alice}, 0, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []common.Address{router, reverts}; !slices.Equal(spenders, want) {
+		t.Fatalf("spenders = %v, want %v", spenders, want)
+	}
+}
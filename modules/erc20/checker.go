@@ -3,8 +3,11 @@ package erc20
 import (
 	"chain-lens/core"
 	"chain-lens/tools"
+	"context"
 	"fmt"
+	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -14,6 +17,9 @@ type Checker struct {
 	Token        *Token
 	Decimals     uint8
 	Symbol       string
+	BlockNumber  *big.Int        // 固定查询的区块高度，nil 表示 latest
+	Context      context.Context // 逐个查询使用的 ctx，nil 表示 context.Background()
+	Logger       core.Logger     // 诊断日志，默认沿用 EvmClient 的日志器
 }
 
 // NewChecker initializes a Checker for the given ERC20 token.
@@ -38,13 +44,15 @@ func NewChecker(tokenAddress common.Address, evmClient *core.EvmClient) (*Checke
 		Token:        token,
 		Decimals:     decimals,
 		Symbol:       symbol,
+		Logger:       evmClient.Log(),
 	}, nil
 }
 
 func (c *Checker) BalanceOf(wallet common.Address) (*core.TokenBalance, error) {
-	rawBalance, err := c.Token.BalanceOf(nil, wallet)
+	rawBalance, err := c.Token.BalanceOf(&bind.CallOpts{BlockNumber: c.BlockNumber, Context: c.Context}, wallet)
 	if err != nil {
-		return nil, fmt.Errorf("查询余额失败: %w", err)
+		core.LoggerOrDefault(c.Logger).Debug("erc20 balanceOf failed", "token", c.TokenAddress, "wallet", wallet, "err", err)
+		return nil, fmt.Errorf("balanceOf failed: %w", err)
 	}
 	readableBalance := tools.WeiToEther(rawBalance, c.Decimals)
 	return &core.TokenBalance{
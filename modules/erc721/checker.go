@@ -2,9 +2,11 @@ package erc721
 
 import (
 	"chain-lens/core"
+	"context"
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -13,6 +15,9 @@ type Checker struct {
 	EvmClient    *core.EvmClient
 	Symbol       string
 	Token        *Erc721
+	BlockNumber  *big.Int        // 固定查询的区块高度，nil 表示 latest
+	Context      context.Context // 逐个查询使用的 ctx，nil 表示 context.Background()
+	Logger       core.Logger     // 诊断日志，默认沿用 EvmClient 的日志器
 }
 
 func NewChecker(tokenAddress common.Address, evmClient *core.EvmClient) (*Checker, error) {
@@ -29,13 +34,15 @@ func NewChecker(tokenAddress common.Address, evmClient *core.EvmClient) (*Checke
 		EvmClient:    evmClient,
 		Symbol:       symbol,
 		Token:        token,
+		Logger:       evmClient.Log(),
 	}, nil
 }
 
 func (c *Checker) BalanceOf(wallet common.Address) (*core.TokenBalance, error) {
-	rawBalance, err := c.Token.BalanceOf(nil, wallet)
+	rawBalance, err := c.Token.BalanceOf(&bind.CallOpts{BlockNumber: c.BlockNumber, Context: c.Context}, wallet)
 	if err != nil {
-		return nil, fmt.Errorf("查询余额失败: %w", err)
+		core.LoggerOrDefault(c.Logger).Debug("erc721 balanceOf failed", "token", c.TokenAddress, "wallet", wallet, "err", err)
+		return nil, fmt.Errorf("balanceOf failed: %w", err)
 	}
 	return &core.TokenBalance{
 		Symbol:       c.Symbol,
@@ -0,0 +1,321 @@
+package erc721
+
+import (
+	"chain-lens/core"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EnumerableInterfaceID ERC721Enumerable 的 ERC165 接口 ID
+var EnumerableInterfaceID = [4]byte{0x78, 0x0e, 0x9d, 0x63}
+
+// EnumerableMetaData ERC721Enumerable 扩展里本模块用到的函数
+var EnumerableMetaData = &bind.MetaData{
+	ABI: `[{"inputs":[{"name":"owner","type":"address"},{"name":"index","type":"uint256"}],"name":"tokenOfOwnerByIndex","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`,
+}
+
+const (
+	DefaultBatchSize   = core.DefaultBatchSize // 每次 Multicall 打包的调用数
+	DefaultMaxPerOwner = 10000                 // 单个钱包最多通过 tokenOfOwnerByIndex 枚举的 ID 数
+	ownerTopicBatch    = 100                   // 回退扫日志时每次放进 topic 过滤的钱包数
+)
+
+// OwnedTokens 某个钱包持有的 NFT
+type OwnedTokens struct {
+	Owner    common.Address `json:"owner"`
+	Balance  *big.Int       `json:"balance"`
+	TokenIDs []*big.Int     `json:"token_ids"`
+	Success  bool           `json:"success"`
+	Tokens   []TokenInfo    `json:"tokens,omitempty"` // 开启元数据解析时填充
+}
+
+// Enumerator 列出钱包持有的具体 Token ID。
+// 合约支持 ERC721Enumerable 时通过 Multicall 批量调用 tokenOfOwnerByIndex；
+// 否则扫描 Transfer 日志重建候选 ID，再用 ownerOf 批量校验当前持有人。
+type Enumerator struct {
+	Token     common.Address
+	EvmClient *core.EvmClient  // 回退扫日志时使用
+	Caller    core.BatchCaller // 批量只读调用 (通常是 MultiChecker)
+	BatchSize int              // 0 表示 DefaultBatchSize
+	FromBlock uint64           // 回退扫日志的起始区块
+	ToBlock   uint64           // 回退扫日志的结束区块，需和 Caller 固定的区块一致
+	Chunk     uint64           // 扫日志的初始分段大小
+	// MaxPerOwner 单个钱包 balanceOf 的上限，0 表示 DefaultMaxPerOwner。
+	// balanceOf 来自合约本身，异常或恶意的合约可能返回极大的值，超过上限时改为扫 Transfer 日志。
+	MaxPerOwner int
+	Logger      core.Logger // 诊断日志，nil 时沿用 EvmClient 的日志器
+}
+
+func NewEnumerator(tokenAddress common.Address, evmClient *core.EvmClient, caller core.BatchCaller) *Enumerator {
+	return &Enumerator{
+		Token:     tokenAddress,
+		EvmClient: evmClient,
+		Caller:    caller,
+		BatchSize: DefaultBatchSize,
+	}
+}
+
+func (e *Enumerator) log() core.Logger {
+	if e.Logger == nil && e.EvmClient != nil {
+		return e.EvmClient.Log()
+	}
+	return core.LoggerOrDefault(e.Logger)
+}
+
+// SupportsEnumerable 通过 ERC165 判断合约是否实现 ERC721Enumerable
+func (e *Enumerator) SupportsEnumerable() bool {
+	parsed, err := Erc721MetaData.GetAbi()
+	if err != nil {
+		return false
+	}
+	data, err := parsed.Pack("supportsInterface", EnumerableInterfaceID)
+	if err != nil {
+		return false
+	}
+	results, err := e.Caller.Aggregate([]core.Call{{Target: e.Token, Data: data}})
+	if err != nil || len(results) == 0 || !results[0].Success {
+		return false
+	}
+	out, err := parsed.Unpack("supportsInterface", results[0].ReturnData)
+	if err != nil || len(out) == 0 {
+		return false
+	}
+	ok, _ := out[0].(bool)
+	return ok
+}
+
+// TokenIDs 返回每个钱包持有的 Token ID，结果顺序与 owners 一致
+func (e *Enumerator) TokenIDs(owners []common.Address) ([]OwnedTokens, error) {
+	result, err := e.balances(owners)
+	if err != nil {
+		return nil, err
+	}
+	if e.SupportsEnumerable() {
+		e.log().Info("ERC721Enumerable supported, using tokenOfOwnerByIndex", "token", e.Token)
+		err := e.enumerate(result)
+		if err == nil {
+			return result, nil
+		}
+		e.log().Warn("tokenOfOwnerByIndex failed, falling back to Transfer logs", "token", e.Token, "err", err)
+		for i := range result {
+			result[i].TokenIDs = []*big.Int{}
+		}
+	}
+	e.log().Info("reconstructing ownership from Transfer logs", "token", e.Token)
+	if err := e.fromLogs(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// balances 批量查询 balanceOf，用于确定每个钱包需要枚举多少个 ID
+func (e *Enumerator) balances(owners []common.Address) ([]OwnedTokens, error) {
+	parsed, err := Erc721MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	calls := make([]core.Call, 0, len(owners))
+	for _, owner := range owners {
+		data, err := parsed.Pack("balanceOf", owner)
+		if err != nil {
+			return nil, fmt.Errorf("pack balanceOf: %w", err)
+		}
+		calls = append(calls, core.Call{Target: e.Token, Data: data})
+	}
+	results, err := e.aggregate(calls)
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]OwnedTokens, len(owners))
+	for i, owner := range owners {
+		owned[i] = OwnedTokens{Owner: owner, Balance: new(big.Int), TokenIDs: []*big.Int{}}
+		if bal, err := unpackUint256(parsed, "balanceOf", results[i]); err == nil {
+			owned[i].Balance = bal
+			owned[i].Success = true
+		}
+	}
+	return owned, nil
+}
+
+// enumerate 用 tokenOfOwnerByIndex(owner, i) 逐个取出 ID
+func (e *Enumerator) enumerate(owned []OwnedTokens) error {
+	parsed, err := EnumerableMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	limit := e.MaxPerOwner
+	if limit <= 0 {
+		limit = DefaultMaxPerOwner
+	}
+	type slot struct{ owner, index int }
+	var calls []core.Call
+	var slots []slot
+	for i, o := range owned {
+		if !o.Success {
+			continue
+		}
+		if !o.Balance.IsInt64() || o.Balance.Int64() > int64(limit) {
+			return fmt.Errorf("balanceOf(%s) = %s exceeds the per-owner limit %d", o.Owner.Hex(), o.Balance, limit)
+		}
+		for j := int64(0); j < o.Balance.Int64(); j++ {
+			data, err := parsed.Pack("tokenOfOwnerByIndex", o.Owner, big.NewInt(j))
+			if err != nil {
+				return fmt.Errorf("pack tokenOfOwnerByIndex: %w", err)
+			}
+			calls = append(calls, core.Call{Target: e.Token, Data: data})
+			slots = append(slots, slot{owner: i, index: int(j)})
+		}
+	}
+	results, err := e.aggregate(calls)
+	if err != nil {
+		return err
+	}
+	for k, res := range results {
+		id, err := unpackUint256(parsed, "tokenOfOwnerByIndex", res)
+		if err != nil {
+			return fmt.Errorf("tokenOfOwnerByIndex(%s, %d): %w", owned[slots[k].owner].Owner.Hex(), slots[k].index, err)
+		}
+		o := &owned[slots[k].owner]
+		o.TokenIDs = append(o.TokenIDs, id)
+	}
+	return nil
+}
+
+// fromLogs 扫描转入这些钱包的 Transfer 事件得到候选 ID，再用 ownerOf 校验当前持有人
+func (e *Enumerator) fromLogs(owned []OwnedTokens) error {
+	if e.EvmClient == nil {
+		return errors.New("log fallback needs an EvmClient")
+	}
+	filterer, err := NewErc721Filterer(e.Token, e.EvmClient.Client)
+	if err != nil {
+		return fmt.Errorf("failed to bind token %s: %w", e.Token.Hex(), err)
+	}
+	to := e.ToBlock
+	if to == 0 {
+		to, err = e.EvmClient.Client.BlockNumber(context.Background())
+		if err != nil {
+			return fmt.Errorf("get block number: %w", err)
+		}
+	}
+
+	var recipients []common.Address
+	for _, o := range owned {
+		if o.Success && o.Balance.Sign() > 0 {
+			recipients = append(recipients, o.Owner)
+		}
+	}
+	candidates := make(map[string]*big.Int)
+	for i := 0; i < len(recipients); i += ownerTopicBatch {
+		batch := recipients[i:min(i+ownerTopicBatch, len(recipients))]
+		err := core.ScanBlockRange(e.FromBlock, to, e.Chunk, func(start, end uint64) error {
+			it, err := filterer.FilterTransfer(&bind.FilterOpts{Start: start, End: &end, Context: context.Background()}, nil, batch, nil)
+			if err != nil {
+				return err
+			}
+			defer it.Close()
+			for it.Next() {
+				candidates[it.Event.TokenId.String()] = it.Event.TokenId
+			}
+			return it.Error()
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	ids := make([]*big.Int, 0, len(candidates))
+	for _, id := range candidates {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) < 0 })
+
+	owners, err := OwnersOf(e.Caller, e.Token, ids, e.BatchSize)
+	if err != nil {
+		return err
+	}
+	index := make(map[common.Address]int, len(owned))
+	for i, o := range owned {
+		index[o.Owner] = i
+	}
+	for _, own := range owners {
+		if !own.Exists {
+			continue
+		}
+		if i, ok := index[own.Owner]; ok {
+			owned[i].TokenIDs = append(owned[i].TokenIDs, own.TokenID)
+		}
+	}
+	return nil
+}
+
+// Ownership 单个 Token ID 的当前持有人，Exists 为 false 表示 ownerOf revert (已销毁或不存在)
+type Ownership struct {
+	TokenID *big.Int       `json:"token_id"`
+	Owner   common.Address `json:"owner"`
+	Exists  bool           `json:"exists"`
+}
+
+// OwnersOf 分批通过 caller 调用 ownerOf，结果顺序与 ids 一致
+func OwnersOf(caller core.BatchCaller, token common.Address, ids []*big.Int, batchSize int) ([]Ownership, error) {
+	parsed, err := Erc721MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	calls := make([]core.Call, 0, len(ids))
+	for _, id := range ids {
+		data, err := parsed.Pack("ownerOf", id)
+		if err != nil {
+			return nil, fmt.Errorf("pack ownerOf: %w", err)
+		}
+		calls = append(calls, core.Call{Target: token, Data: data})
+	}
+	results, err := core.AggregateInBatches(caller, calls, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	owners := make([]Ownership, len(ids))
+	for i, res := range results {
+		owners[i] = Ownership{TokenID: ids[i]}
+		if !res.Success {
+			continue
+		}
+		out, err := parsed.Unpack("ownerOf", res.ReturnData)
+		if err != nil || len(out) == 0 {
+			continue
+		}
+		owner, _ := out[0].(common.Address)
+		// 有的合约对不存在的 ID 返回零地址而不是 revert
+		owners[i].Owner = owner
+		owners[i].Exists = owner != (common.Address{})
+	}
+	return owners, nil
+}
+
+func (e *Enumerator) aggregate(calls []core.Call) ([]core.CallResult, error) {
+	return core.AggregateInBatches(e.Caller, calls, e.BatchSize)
+}
+
+func unpackUint256(parsed *abi.ABI, method string, res core.CallResult) (*big.Int, error) {
+	if !res.Success {
+		return nil, errors.New("call reverted")
+	}
+	out, err := parsed.Unpack(method, res.ReturnData)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no data unpacked")
+	}
+	v, ok := out[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("result is not *big.Int, type is %T", out[0])
+	}
+	return v, nil
+}
@@ -0,0 +1,232 @@
+package erc721
+
+import (
+	"bytes"
+	"chain-lens/core"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeNFT 模拟一个 ERC721 合约，按 calldata 直接返回结果；nonEnumerable 时不支持 ERC721Enumerable，
+// balances 覆盖 balanceOf 的返回值 (模拟异常合约)
+type fakeNFT struct {
+	t             *testing.T
+	owners        map[common.Address][]*big.Int
+	nonEnumerable bool
+	balances      map[common.Address]*big.Int
+}
+
+func (f *fakeNFT) Aggregate(calls []core.Call) ([]core.CallResult, error) {
+	nft, _ := Erc721MetaData.GetAbi()
+	enum, _ := EnumerableMetaData.GetAbi()
+	var results []core.CallResult
+	for _, c := range calls {
+		var (
+			out []byte
+			err error
+		)
+		switch {
+		case bytes.HasPrefix(c.Data, nft.Methods["supportsInterface"].ID):
+			out, err = nft.Methods["supportsInterface"].Outputs.Pack(!f.nonEnumerable)
+		case bytes.HasPrefix(c.Data, nft.Methods["ownerOf"].ID):
+			args, _ := nft.Methods["ownerOf"].Inputs.Unpack(c.Data[4:])
+			owner, ok := f.ownerOf(args[0].(*big.Int))
+			if !ok {
+				results = append(results, core.CallResult{})
+				continue
+			}
+			out, err = nft.Methods["ownerOf"].Outputs.Pack(owner)
+		case bytes.HasPrefix(c.Data, nft.Methods["balanceOf"].ID):
+			args, _ := nft.Methods["balanceOf"].Inputs.Unpack(c.Data[4:])
+			owner := args[0].(common.Address)
+			balance, ok := f.balances[owner]
+			if !ok {
+				balance = big.NewInt(int64(len(f.owners[owner])))
+			}
+			out, err = nft.Methods["balanceOf"].Outputs.Pack(balance)
+		case bytes.HasPrefix(c.Data, enum.Methods["tokenOfOwnerByIndex"].ID) && !f.nonEnumerable:
+			args, _ := enum.Methods["tokenOfOwnerByIndex"].Inputs.Unpack(c.Data[4:])
+			owner, index := args[0].(common.Address), args[1].(*big.Int)
+			out, err = enum.Methods["tokenOfOwnerByIndex"].Outputs.Pack(f.owners[owner][index.Int64()])
+		default:
+			results = append(results, core.CallResult{})
+			continue
+		}
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		results = append(results, core.CallResult{Success: true, ReturnData: out})
+	}
+	return results, nil
+}
+
+func (f *fakeNFT) ownerOf(id *big.Int) (common.Address, bool) {
+	for owner, ids := range f.owners {
+		for _, owned := range ids {
+			if owned.Cmp(id) == 0 {
+				return owner, true
+			}
+		}
+	}
+	return common.Address{}, false
+}
+
+func TestEnumeratorTokenOfOwnerByIndex(t *testing.T) {
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	carol := common.HexToAddress("0xc0")
+	fake := &fakeNFT{t: t, owners: map[common.Address][]*big.Int{
+		alice: {big.NewInt(7), big.NewInt(42)},
+		bob:   {big.NewInt(1)},
+	}}
+
+	e := NewEnumerator(common.HexToAddress("0x721"), nil, fake)
+	e.BatchSize = 2 // 强制拆成多个批次
+	owned, err := e.TokenIDs([]common.Address{alice, bob, carol})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owned) != 3 {
+		t.Fatalf("expected 3 owners, got %d", len(owned))
+	}
+	if len(owned[0].TokenIDs) != 2 || owned[0].TokenIDs[1].Int64() != 42 {
+		t.Fatalf("unexpected alice tokens: %v", owned[0].TokenIDs)
+	}
+	if len(owned[1].TokenIDs) != 1 || owned[1].TokenIDs[0].Int64() != 1 {
+		t.Fatalf("unexpected bob tokens: %v", owned[1].TokenIDs)
+	}
+	if !owned[2].Success || len(owned[2].TokenIDs) != 0 {
+		t.Fatalf("unexpected carol result: %+v", owned[2])
+	}
+}
+
+// fakeLogs 进程内的假节点，实现 eth_getLogs (按合约地址、区块和 topic 过滤)
+type fakeLogs struct {
+	logs []types.Log
+}
+
+type filterArg struct {
+	Address   []common.Address `json:"address"`
+	FromBlock hexutil.Uint64   `json:"fromBlock"`
+	ToBlock   hexutil.Uint64   `json:"toBlock"`
+	Topics    [][]common.Hash  `json:"topics"`
+}
+
+func (f *fakeLogs) GetLogs(arg filterArg) ([]types.Log, error) {
+	var out []types.Log
+	for _, l := range f.logs {
+		if l.BlockNumber < uint64(arg.FromBlock) || l.BlockNumber > uint64(arg.ToBlock) || !slices.Contains(arg.Address, l.Address) {
+			continue
+		}
+		match := true
+		for i, want := range arg.Topics {
+			if len(want) > 0 && (i >= len(l.Topics) || !slices.Contains(want, l.Topics[i])) {
+				match = false
+			}
+		}
+		if match {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func transferLog(token common.Address, block uint64, from, to common.Address, id int64) types.Log {
+	parsed, _ := Erc721MetaData.GetAbi()
+	return types.Log{
+		Address: token,
+		Topics: []common.Hash{
+			parsed.Events["Transfer"].ID,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+			common.BigToHash(big.NewInt(id)),
+		},
+		BlockNumber: block,
+	}
+}
+
+func TestEnumeratorFromLogs(t *testing.T) {
+	token := common.HexToAddress("0x721")
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	carol := common.HexToAddress("0xc0")
+	zero := common.Address{}
+	// alice 铸造 7 和 42，随后把 7 转给 bob；bob 另外铸造了 1
+	fake := &fakeNFT{t: t, nonEnumerable: true, owners: map[common.Address][]*big.Int{
+		alice: {big.NewInt(42)},
+		bob:   {big.NewInt(1), big.NewInt(7)},
+	}}
+	logs := &fakeLogs{logs: []types.Log{
+		transferLog(token, 10, zero, alice, 7),
+		transferLog(token, 11, zero, alice, 42),
+		transferLog(token, 20, zero, bob, 1),
+		transferLog(token, 30, alice, bob, 7),
+	}}
+	node := rpc.NewServer()
+	if err := node.RegisterName("eth", logs); err != nil {
+		t.Fatal(err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(node))
+	t.Cleanup(client.Close)
+
+	e := NewEnumerator(token, &core.EvmClient{Client: client, Logger: core.NopLogger()}, fake)
+	e.ToBlock = 100
+	owned, err := e.TokenIDs([]common.Address{alice, bob, carol})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := func(o OwnedTokens) []int64 {
+		var out []int64
+		for _, id := range o.TokenIDs {
+			out = append(out, id.Int64())
+		}
+		return out
+	}
+	if got := ids(owned[0]); !slices.Equal(got, []int64{42}) {
+		t.Errorf("alice tokens = %v, want [42] (7 was transferred away)", got)
+	}
+	if got := ids(owned[1]); !slices.Equal(got, []int64{1, 7}) {
+		t.Errorf("bob tokens = %v, want [1 7]", got)
+	}
+	if !owned[2].Success || len(owned[2].TokenIDs) != 0 {
+		t.Errorf("unexpected carol result: %+v", owned[2])
+	}
+}
+
+func TestEnumeratorBalanceLimit(t *testing.T) {
+	token := common.HexToAddress("0x721")
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	// 合约支持 Enumerable，但 bob 的 balanceOf 返回一个不可能的值：不能按它逐个枚举，改为扫日志
+	fake := &fakeNFT{t: t, owners: map[common.Address][]*big.Int{
+		alice: {big.NewInt(42)},
+		bob:   {big.NewInt(1)},
+	}, balances: map[common.Address]*big.Int{bob: new(big.Int).Lsh(big.NewInt(1), 200)}}
+	logs := &fakeLogs{logs: []types.Log{
+		transferLog(token, 10, common.Address{}, alice, 42),
+		transferLog(token, 20, common.Address{}, bob, 1),
+	}}
+	node := rpc.NewServer()
+	if err := node.RegisterName("eth", logs); err != nil {
+		t.Fatal(err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(node))
+	t.Cleanup(client.Close)
+
+	e := NewEnumerator(token, &core.EvmClient{Client: client, Logger: core.NopLogger()}, fake)
+	e.ToBlock = 100
+	owned, err := e.TokenIDs([]common.Address{alice, bob})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owned[0].TokenIDs) != 1 || owned[0].TokenIDs[0].Int64() != 42 || len(owned[1].TokenIDs) != 1 || owned[1].TokenIDs[0].Int64() != 1 {
+		t.Fatalf("unexpected tokens: alice %v, bob %v", owned[0].TokenIDs, owned[1].TokenIDs)
+	}
+}
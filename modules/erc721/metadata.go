@@ -0,0 +1,243 @@
+package erc721
+
+import (
+	"chain-lens/core"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	DefaultIPFSGateway  = "https://ipfs.io/ipfs/"
+	DefaultFetchWorkers = 8                // 并发拉取元数据的协程数
+	maxMetadataSize     = 4 << 20          // 单个元数据 JSON 最大 4MB
+	metadataTimeout     = 15 * time.Second // 单次 HTTP 请求超时
+)
+
+// Metadata tokenURI 指向的 JSON 元数据 (ERC721 Metadata JSON Schema + OpenSea 的 attributes)
+type Metadata struct {
+	Name        string      `json:"name,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Image       string      `json:"image,omitempty"`
+	Attributes  []Attribute `json:"attributes,omitempty"`
+}
+
+// Attribute 元数据里的一条属性，value 可能是字符串或数字
+type Attribute struct {
+	TraitType string `json:"trait_type,omitempty"`
+	Value     any    `json:"value"`
+}
+
+// TokenInfo 单个 Token 的 URI 和解析后的元数据
+type TokenInfo struct {
+	TokenID  *big.Int  `json:"token_id"`
+	URI      string    `json:"uri,omitempty"`
+	Metadata *Metadata `json:"metadata,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// TokenURIs 分批通过 caller 调用 tokenURI，结果顺序与 ids 一致；调用失败的位置为空字符串
+func TokenURIs(caller core.BatchCaller, token common.Address, ids []*big.Int, batchSize int) ([]string, error) {
+	parsed, err := Erc721MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	calls := make([]core.Call, 0, len(ids))
+	for _, id := range ids {
+		data, err := parsed.Pack("tokenURI", id)
+		if err != nil {
+			return nil, fmt.Errorf("pack tokenURI: %w", err)
+		}
+		calls = append(calls, core.Call{Target: token, Data: data})
+	}
+	results, err := core.AggregateInBatches(caller, calls, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	uris := make([]string, len(ids))
+	for i, res := range results {
+		if !res.Success {
+			continue
+		}
+		out, err := parsed.Unpack("tokenURI", res.ReturnData)
+		if err != nil || len(out) == 0 {
+			continue
+		}
+		uris[i], _ = out[0].(string)
+	}
+	return uris, nil
+}
+
+// MetadataResolver 解析 tokenURI：支持 data:、http(s):// 和 ipfs:// (经网关)，
+// 远程拉取的 JSON 会缓存在 CacheDir 下，重复运行不再请求网络。
+type MetadataResolver struct {
+	Gateway    string // IPFS 网关前缀，例如 https://ipfs.io/ipfs/
+	CacheDir   string // 为空则不缓存
+	HTTPClient *http.Client
+	Workers    int
+}
+
+func NewMetadataResolver(gateway, cacheDir string) *MetadataResolver {
+	if gateway == "" {
+		gateway = DefaultIPFSGateway
+	}
+	if !strings.HasSuffix(gateway, "/") {
+		gateway += "/"
+	}
+	return &MetadataResolver{
+		Gateway:    gateway,
+		CacheDir:   cacheDir,
+		HTTPClient: &http.Client{Timeout: metadataTimeout},
+		Workers:    DefaultFetchWorkers,
+	}
+}
+
+// Resolve 解析单个 tokenURI 指向的元数据
+func (r *MetadataResolver) Resolve(uri string) (*Metadata, error) {
+	raw, err := r.load(strings.TrimSpace(uri))
+	if err != nil {
+		return nil, err
+	}
+	var md Metadata
+	if err := json.Unmarshal(raw, &md); err != nil {
+		return nil, fmt.Errorf("parse metadata: %w", err)
+	}
+	return &md, nil
+}
+
+// ResolveAll 并发解析多个 Token 的元数据，结果顺序与 ids 一致
+func (r *MetadataResolver) ResolveAll(ids []*big.Int, uris []string) []TokenInfo {
+	infos := make([]TokenInfo, len(ids))
+	workers := r.Workers
+	if workers <= 0 {
+		workers = DefaultFetchWorkers
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i := range ids {
+		infos[i] = TokenInfo{TokenID: ids[i], URI: uris[i]}
+		if uris[i] == "" {
+			infos[i].Error = "tokenURI call failed"
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(info *TokenInfo) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			md, err := r.Resolve(info.URI)
+			if err != nil {
+				info.Error = err.Error()
+				return
+			}
+			info.Metadata = md
+		}(&infos[i])
+	}
+	wg.Wait()
+	return infos
+}
+
+// GatewayURL 把 ipfs:// 地址转换成网关地址，其他地址原样返回
+func (r *MetadataResolver) GatewayURL(uri string) string {
+	if !strings.HasPrefix(uri, "ipfs://") {
+		return uri
+	}
+	path := strings.TrimPrefix(uri, "ipfs://")
+	path = strings.TrimPrefix(path, "ipfs/")
+	return r.Gateway + path
+}
+
+func (r *MetadataResolver) load(uri string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(uri, "data:"):
+		return decodeDataURI(uri)
+	case strings.HasPrefix(uri, "ipfs://"), strings.HasPrefix(uri, "http://"), strings.HasPrefix(uri, "https://"):
+		target := r.GatewayURL(uri)
+		if data, ok := r.readCache(target); ok {
+			return data, nil
+		}
+		data, err := r.fetch(target)
+		if err != nil {
+			return nil, err
+		}
+		r.writeCache(target, data)
+		return data, nil
+	case uri == "":
+		return nil, errors.New("empty tokenURI")
+	default:
+		return nil, fmt.Errorf("unsupported tokenURI scheme: %s", uri)
+	}
+}
+
+func (r *MetadataResolver) fetch(target string) ([]byte, error) {
+	resp, err := r.HTTPClient.Get(target)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", target, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize))
+}
+
+func (r *MetadataResolver) cachePath(target string) string {
+	sum := sha256.Sum256([]byte(target))
+	return filepath.Join(r.CacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (r *MetadataResolver) readCache(target string) ([]byte, bool) {
+	if r.CacheDir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(r.cachePath(target))
+	return data, err == nil
+}
+
+func (r *MetadataResolver) writeCache(target string, data []byte) {
+	if r.CacheDir == "" {
+		return
+	}
+	// 只缓存合法 JSON，避免把网关的错误页写进缓存
+	if !json.Valid(data) {
+		return
+	}
+	if err := os.MkdirAll(r.CacheDir, 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(r.cachePath(target), data, 0o644)
+}
+
+// decodeDataURI 解析 data:[<mediatype>][;base64],<data>
+func decodeDataURI(uri string) ([]byte, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return nil, errors.New("malformed data URI")
+	}
+	if strings.HasSuffix(header, ";base64") {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, fmt.Errorf("decode base64 data URI: %w", err)
+		}
+		return data, nil
+	}
+	data, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode data URI: %w", err)
+	}
+	return []byte(data), nil
+}
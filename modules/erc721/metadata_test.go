@@ -0,0 +1,67 @@
+package erc721
+
+import (
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMetadataResolver(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hits.Add(1)
+		switch req.URL.Path {
+		case "/ipfs/QmHash/1":
+			w.Write([]byte(`{"name":"Lens #1","image":"ipfs://QmImage/1.png","attributes":[{"trait_type":"Eyes","value":"Laser"},{"trait_type":"Level","value":3}]}`))
+		case "/meta/2.json":
+			w.Write([]byte(`{"name":"Lens #2","image":"https://example.com/2.png"}`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+
+	cacheDir := t.TempDir()
+	r := NewMetadataResolver(srv.URL+"/ipfs", cacheDir)
+
+	md, err := r.Resolve("ipfs://QmHash/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Name != "Lens #1" || len(md.Attributes) != 2 || md.Attributes[0].Value != "Laser" {
+		t.Fatalf("unexpected metadata: %+v", md)
+	}
+
+	b64 := base64.StdEncoding.EncodeToString([]byte(`{"name":"Onchain #3"}`))
+	uris := []string{
+		srv.URL + "/meta/2.json",
+		"data:application/json;base64," + b64,
+		`data:application/json,{"name":"Plain%20#4"}`,
+		"",
+		srv.URL + "/missing",
+	}
+	ids := []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5), big.NewInt(6)}
+	infos := r.ResolveAll(ids, uris)
+	if infos[0].Metadata == nil || infos[0].Metadata.Name != "Lens #2" {
+		t.Fatalf("unexpected http metadata: %+v", infos[0])
+	}
+	if infos[1].Metadata == nil || infos[1].Metadata.Name != "Onchain #3" {
+		t.Fatalf("unexpected base64 data URI metadata: %+v", infos[1])
+	}
+	if infos[2].Metadata == nil || infos[2].Metadata.Name != "Plain #4" {
+		t.Fatalf("unexpected plain data URI metadata: %+v", infos[2])
+	}
+	if infos[3].Error == "" || infos[4].Error == "" {
+		t.Fatalf("expected errors for empty and missing URIs: %+v %+v", infos[3], infos[4])
+	}
+
+	// 关掉服务后仍能从本地缓存读出
+	srv.Close()
+	before := hits.Load()
+	md, err = r.Resolve("ipfs://QmHash/1")
+	if err != nil || md.Name != "Lens #1" || hits.Load() != before {
+		t.Fatalf("expected cached metadata, got %+v err=%v", md, err)
+	}
+}
@@ -0,0 +1,115 @@
+package holders
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/erc20"
+	"chain-lens/modules/erc721"
+	"chain-lens/modules/multicall"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Scanner 通过扫描 Transfer 事件发现代币的所有历史持有人
+type Scanner struct {
+	EvmClient *core.EvmClient
+	Token     common.Address
+	Type      multicall.TokenType
+	Chunk     uint64 // 初始分段大小，0 表示 core.DefaultLogChunk
+}
+
+func NewScanner(evmClient *core.EvmClient, tType multicall.TokenType, token common.Address) (*Scanner, error) {
+	if tType == multicall.TokenTypeNative {
+		return nil, errors.New("native token has no Transfer events, holder scan needs erc20 or erc721")
+	}
+	return &Scanner{
+		EvmClient: evmClient,
+		Token:     token,
+		Type:      tType,
+	}, nil
+}
+
+// Collect 扫描 [from, to] 区块内的 Transfer 事件，返回出现过的所有地址 (按首次出现顺序)。
+// 零地址 (mint/burn) 会被排除。
+func (s *Scanner) Collect(from, to uint64) ([]common.Address, error) {
+	return s.CollectContext(context.Background(), from, to)
+}
+
+// CollectContext 同 Collect，ctx 取消或超时后停止扫描并返回 ctx 的错误
+func (s *Scanner) CollectContext(ctx context.Context, from, to uint64) ([]common.Address, error) {
+	seen := make(map[common.Address]bool)
+	var addrs []common.Address
+	add := func(addr common.Address) {
+		if addr == (common.Address{}) || seen[addr] {
+			return
+		}
+		seen[addr] = true
+		addrs = append(addrs, addr)
+	}
+
+	var fetch func(start, end uint64) error
+	switch s.Type {
+	case multicall.TokenTypeERC20:
+		filterer, err := erc20.NewTokenFilterer(s.Token, s.EvmClient.Client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind token %s: %w", s.Token.Hex(), err)
+		}
+		fetch = func(start, end uint64) error {
+			it, err := filterer.FilterTransfer(filterOpts(ctx, start, end), nil, nil)
+			if err != nil {
+				return err
+			}
+			defer it.Close()
+			for it.Next() {
+				add(it.Event.From)
+				add(it.Event.To)
+			}
+			return it.Error()
+		}
+	case multicall.TokenTypeERC721:
+		filterer, err := erc721.NewErc721Filterer(s.Token, s.EvmClient.Client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind token %s: %w", s.Token.Hex(), err)
+		}
+		fetch = func(start, end uint64) error {
+			it, err := filterer.FilterTransfer(filterOpts(ctx, start, end), nil, nil, nil)
+			if err != nil {
+				return err
+			}
+			defer it.Close()
+			for it.Next() {
+				add(it.Event.From)
+				add(it.Event.To)
+			}
+			return it.Error()
+		}
+	default:
+		return nil, errors.New("unknown token type")
+	}
+
+	chunks := 0
+	err := core.ScanBlockRange(from, to, s.Chunk, func(start, end uint64) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fetch(start, end); err != nil {
+			return err
+		}
+		chunks++
+		if chunks%20 == 0 {
+			s.EvmClient.Log().Info("scanning Transfer logs", "token", s.Token, "block", end, "addresses", len(addrs))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addrs, nil
+}
+
+func filterOpts(ctx context.Context, start, end uint64) *bind.FilterOpts {
+	return &bind.FilterOpts{Start: start, End: &end, Context: ctx}
+}
@@ -0,0 +1,90 @@
+package holders
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/erc20"
+	"chain-lens/modules/multicall"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeLogs 进程内的假节点，只实现 eth_getLogs；查询范围超过 maxRange 时返回范围限制错误
+type fakeLogs struct {
+	logs     []types.Log
+	maxRange uint64
+}
+
+type filterArg struct {
+	Address   []common.Address `json:"address"`
+	FromBlock hexutil.Uint64   `json:"fromBlock"`
+	ToBlock   hexutil.Uint64   `json:"toBlock"`
+	Topics    [][]common.Hash  `json:"topics"`
+}
+
+func (f *fakeLogs) GetLogs(arg filterArg) ([]types.Log, error) {
+	if uint64(arg.ToBlock-arg.FromBlock)+1 > f.maxRange {
+		return nil, errors.New("query exceeds max block range")
+	}
+	var out []types.Log
+	for _, l := range f.logs {
+		if l.BlockNumber >= uint64(arg.FromBlock) && l.BlockNumber <= uint64(arg.ToBlock) && slices.Contains(arg.Address, l.Address) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func transferLog(token common.Address, block uint64, from, to common.Address, amount int64) types.Log {
+	parsed, _ := erc20.TokenMetaData.GetAbi()
+	return types.Log{
+		Address:     token,
+		Topics:      []common.Hash{parsed.Events["Transfer"].ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        common.BigToHash(big.NewInt(amount)).Bytes(),
+		BlockNumber: block,
+	}
+}
+
+func TestCollect(t *testing.T) {
+	token := common.HexToAddress("0x20")
+	alice, bob, carol := common.HexToAddress("0xa1"), common.HexToAddress("0xb0"), common.HexToAddress("0xc0")
+	zero := common.Address{}
+	fake := &fakeLogs{maxRange: 100, logs: []types.Log{
+		transferLog(token, 10, zero, alice, 100), // mint
+		transferLog(token, 150, alice, bob, 40),
+		transferLog(token, 420, bob, alice, 10),
+		transferLog(token, 900, alice, carol, 5),
+		transferLog(token, 950, carol, zero, 5), // burn
+		transferLog(common.HexToAddress("0x21"), 500, common.HexToAddress("0xdd"), alice, 1),
+	}}
+	node := rpc.NewServer()
+	if err := node.RegisterName("eth", fake); err != nil {
+		t.Fatal(err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(node))
+	t.Cleanup(client.Close)
+
+	s, err := NewScanner(&core.EvmClient{Client: client, Logger: core.NopLogger()}, multicall.TokenTypeERC20, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Chunk = 500 // 大于节点限制，需要自动缩小
+	addrs, err := s.Collect(0, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []common.Address{alice, bob, carol}; !slices.Equal(addrs, want) {
+		t.Fatalf("holders = %v, want %v", addrs, want)
+	}
+
+	if _, err := NewScanner(nil, multicall.TokenTypeNative, common.Address{}); err == nil {
+		t.Fatal("native token must be rejected")
+	}
+}
@@ -2,23 +2,25 @@ package multicall
 
 import (
 	"chain-lens/core"
+	"chain-lens/metrics"
 	"chain-lens/modules/erc20"
 	"chain-lens/modules/erc721"
 	"chain-lens/tools"
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type TokenType int
 
-const ContractAddress = "0xcA11bde05977b3631167028862bE2a173976CA11"
-
 const (
 	TokenTypeERC20 TokenType = iota
 	TokenTypeERC721
@@ -29,6 +31,17 @@ type MultiChecker struct {
 	Client        *ethclient.Client
 	Multicall     *Multicall
 	MulticallAddr common.Address
+	BlockNumber   *big.Int       // 固定查询的区块高度，nil 表示 latest
+	Chain         core.ChainInfo // 原生币符号/精度、Multicall3 部署区块
+	// Strategy 批量方式，默认 auto：第一次查询前按节点支持情况选择
+	Strategy Strategy
+	Logger   core.Logger // 诊断日志，nil 表示 core.DefaultLogger
+	// OnBatch 每次批量调用成功返回后回调：子调用数和其中失败的数量，用于进度统计
+	OnBatch func(calls, failed int)
+	// Context 批量调用使用的 ctx，取消或超时后进行中的请求随之结束；nil 表示 context.Background()
+	Context     context.Context
+	mode        Strategy
+	resolveOnce sync.Once
 }
 
 type callItem struct {
@@ -39,8 +52,14 @@ type callItem struct {
 	AbiName  string
 }
 
+// NewMultiChecker 使用标准 Multicall3 地址和 ETH 作为原生币
 func NewMultiChecker(client *ethclient.Client) (*MultiChecker, error) {
-	multicallAddr := common.HexToAddress(ContractAddress)
+	return NewMultiCheckerForChain(client, core.LookupChain(nil, nil))
+}
+
+// NewMultiCheckerForChain 按链信息选择 Multicall3 地址和原生币符号
+func NewMultiCheckerForChain(client *ethclient.Client, chain core.ChainInfo) (*MultiChecker, error) {
+	multicallAddr := chain.Multicall3
 	// 绑定multicall合约
 	multi, err := NewMulticall(multicallAddr, client)
 	if err != nil {
@@ -50,9 +69,54 @@ func NewMultiChecker(client *ethclient.Client) (*MultiChecker, error) {
 		Client:        client,
 		Multicall:     multi,
 		MulticallAddr: multicallAddr,
+		Chain:         chain,
 	}, nil
 }
 
+// aggregate3 执行 Aggregate3，不走链上 Multicall3 时改用对应的 BatchCaller
+func (m *MultiChecker) aggregate3(mcCalls []Multicall3Call3) ([]Multicall3Result, error) {
+	mode := m.resolveStrategy()
+	if mode == StrategyMulticall {
+		resp, err := m.Multicall.Aggregate3(&bind.CallOpts{BlockNumber: m.BlockNumber, Context: m.Context}, mcCalls)
+		if err != nil {
+			return nil, fmt.Errorf("multicall aggregate3 failed: %w", err)
+		}
+		failed := 0
+		for _, r := range resp {
+			if !r.Success {
+				failed++
+			}
+		}
+		m.observeBatch(mode, len(mcCalls), failed)
+		return resp, nil
+	}
+	calls := make([]core.Call, 0, len(mcCalls))
+	for _, c := range mcCalls {
+		calls = append(calls, core.Call{Target: c.Target, Data: c.CallData})
+	}
+	results, err := m.caller(mode).Aggregate(calls)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]Multicall3Result, 0, len(results))
+	failed := 0
+	for _, r := range results {
+		if !r.Success {
+			failed++
+		}
+		resp = append(resp, Multicall3Result{Success: r.Success, ReturnData: r.ReturnData})
+	}
+	m.observeBatch(mode, len(mcCalls), failed)
+	return resp, nil
+}
+
+func (m *MultiChecker) observeBatch(mode Strategy, calls, failed int) {
+	metrics.ObserveBatch(string(mode), calls, failed)
+	if m.OnBatch != nil {
+		m.OnBatch(calls, failed)
+	}
+}
+
 func (m *MultiChecker) CheckToken(tType TokenType, tokenAddr common.Address, owners []common.Address) ([]core.TokenBalance, error) {
 	var callList []callItem
 	var decimals uint8
@@ -75,11 +139,11 @@ func (m *MultiChecker) CheckToken(tType TokenType, tokenAddr common.Address, own
 			return nil, fmt.Errorf("failed to bind token %s: %w", tokenAddr.Hex(), err)
 		}
 		// 查询代币精度
-		decimals, err = token.Decimals(nil)
+		decimals, err = token.Decimals(&bind.CallOpts{BlockNumber: m.BlockNumber, Context: m.Context})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get decimals for token %s: %w", tokenAddr.Hex(), err)
 		}
-		symbol, err = token.Symbol(nil)
+		symbol, err = token.Symbol(&bind.CallOpts{BlockNumber: m.BlockNumber, Context: m.Context})
 		if err != nil {
 			symbol = "UNKNOWN"
 		}
@@ -92,8 +156,8 @@ func (m *MultiChecker) CheckToken(tType TokenType, tokenAddr common.Address, own
 		}
 		callList = append(callList, items...)
 	case TokenTypeNative:
-		decimals = 18
-		symbol = "ETH"
+		decimals = m.Chain.NativeDecimals
+		symbol = m.Chain.NativeSymbol
 		for _, owner := range owners {
 			callData, err := mcAbi.Pack("getEthBalance", owner)
 			if err != nil {
@@ -113,11 +177,17 @@ func (m *MultiChecker) CheckToken(tType TokenType, tokenAddr common.Address, own
 	}
 
 	var mcCalls []Multicall3Call3
+	direct := m.resolveStrategy() != StrategyMulticall
 	for _, c := range callList {
 		target := c.Token
 		// 关键修正：如果查原生代币，Target 必须是 Multicall 合约地址本身
 		if c.Type == TokenTypeNative {
 			target = m.MulticallAddr
+			// 不走链上 Multicall3 时没有 getEthBalance，空 calldata 表示直接读取目标地址的余额
+			if direct {
+				target = c.Owner
+				c.CallData = nil
+			}
 		}
 
 		mcCalls = append(mcCalls, Multicall3Call3{
@@ -127,9 +197,9 @@ func (m *MultiChecker) CheckToken(tType TokenType, tokenAddr common.Address, own
 		})
 	}
 	// 执行multicall3的Aggregate3,把多个合约调用封装（Pack）成一个大调用，一次性发给区块链执行
-	resp, err := m.Multicall.Aggregate3(nil, mcCalls)
+	resp, err := m.aggregate3(mcCalls)
 	if err != nil {
-		return nil, fmt.Errorf("multicall aggregate3 failed: %w", err)
+		return nil, err
 	}
 	var balances []core.TokenBalance
 	erc20Abi, _ := erc20.TokenMetaData.GetAbi()
@@ -231,3 +301,57 @@ func decodeUint256(parsedAbi *abi.ABI, method string, data []byte) (*big.Int, er
 	}
 	return balance, nil
 }
+
+// Aggregate 通过 Aggregate3 一次性执行任意只读调用，单个调用失败不影响其他调用
+func (m *MultiChecker) Aggregate(calls []core.Call) ([]core.CallResult, error) {
+	mcCalls := make([]Multicall3Call3, 0, len(calls))
+	for _, c := range calls {
+		mcCalls = append(mcCalls, Multicall3Call3{
+			Target:       c.Target,
+			CallData:     c.Data,
+			AllowFailure: true,
+		})
+	}
+	resp, err := m.aggregate3(mcCalls)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]core.CallResult, 0, len(resp))
+	for _, r := range resp {
+		results = append(results, core.CallResult{Success: r.Success, ReturnData: r.ReturnData})
+	}
+	return results, nil
+}
+
+// TotalSupply 在一次 Multicall 里查询代币的 totalSupply 和 decimals，返回可读的总供应量。
+// ERC721 的 totalSupply 来自可选的 Enumerable 扩展，合约不支持时返回错误。
+func (m *MultiChecker) TotalSupply(tType TokenType, tokenAddr common.Address) (*big.Float, error) {
+	if tType == TokenTypeNative {
+		return nil, errors.New("native token has no totalSupply")
+	}
+	calls := []core.Call{{Target: tokenAddr, Data: selector("totalSupply()")}}
+	if tType == TokenTypeERC20 {
+		calls = append(calls, core.Call{Target: tokenAddr, Data: selector("decimals()")})
+	}
+	results, err := m.Aggregate(calls)
+	if err != nil {
+		return nil, err
+	}
+	if !results[0].Success || len(results[0].ReturnData) < 32 {
+		return nil, fmt.Errorf("token %s does not support totalSupply", tokenAddr.Hex())
+	}
+	rawSupply := new(big.Int).SetBytes(results[0].ReturnData[:32])
+	if tType == TokenTypeERC721 {
+		return new(big.Float).SetInt(rawSupply), nil
+	}
+	if !results[1].Success || len(results[1].ReturnData) < 32 {
+		return nil, fmt.Errorf("failed to get decimals for token %s", tokenAddr.Hex())
+	}
+	decimals := new(big.Int).SetBytes(results[1].ReturnData[:32])
+	return tools.WeiToEther(rawSupply, uint8(decimals.Uint64())), nil
+}
+
+// selector 计算无参函数签名的 4 字节选择器
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
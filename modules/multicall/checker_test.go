@@ -0,0 +1,81 @@
+package multicall
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/erc20"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// pinnedNode 假节点：模拟一个 ERC20，记录每个方法被查询时使用的区块
+type pinnedNode struct {
+	mu     sync.Mutex
+	blocks map[string]string
+}
+
+// pinnedCallArgs ethclient 把 calldata 放在 input，批量请求放在 data
+type pinnedCallArgs struct {
+	To    common.Address `json:"to"`
+	Input hexutil.Bytes  `json:"input"`
+	Data  hexutil.Bytes  `json:"data"`
+}
+
+func (n *pinnedNode) Call(args pinnedCallArgs, block string) (hexutil.Bytes, error) {
+	parsed, _ := erc20.TokenMetaData.GetAbi()
+	data := args.Input
+	if len(data) == 0 {
+		data = args.Data
+	}
+	method, err := parsed.MethodById(data)
+	if err != nil {
+		return nil, errors.New("execution reverted")
+	}
+	n.mu.Lock()
+	n.blocks[method.Name] = block
+	n.mu.Unlock()
+	switch method.Name {
+	case "decimals":
+		return method.Outputs.Pack(uint8(6))
+	case "symbol":
+		return method.Outputs.Pack("TKN")
+	default:
+		return method.Outputs.Pack(big.NewInt(5_000_000))
+	}
+}
+
+func TestCheckTokenPinnedBlock(t *testing.T) {
+	node := &pinnedNode{blocks: map[string]string{}}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", node); err != nil {
+		t.Fatal(err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	t.Cleanup(client.Close)
+
+	m, err := NewMultiChecker(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Strategy = StrategyRPCBatch
+	m.Logger = core.NopLogger()
+	balances, err := m.AtBlock(big.NewInt(42)).CheckToken(TokenTypeERC20, common.HexToAddress("0x70"), []common.Address{common.HexToAddress("0xa1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1 || balances[0].Symbol != "TKN" || balances[0].Balance.Text('f', -1) != "5" {
+		t.Fatalf("unexpected balances: %+v", balances)
+	}
+	// 精度和 symbol 与余额查在同一个区块上
+	for _, name := range []string{"decimals", "symbol", "balanceOf"} {
+		if node.blocks[name] != "0x2a" {
+			t.Errorf("%s queried at %q, want block 0x2a", name, node.blocks[name])
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package multicall
+
+import (
+	"chain-lens/core"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DeploylessBatchSize 单次无部署调用的最大子调用数。
+// 结果通过“合约代码”返回，受 EIP-170 的 24KB 上限限制，balanceOf 每条结果约 96 字节。
+const DeploylessBatchSize = 200
+
+// 聚合器用到的 EVM 操作码
+const (
+	opAdd            = 0x01
+	opLt             = 0x10
+	opIsZero         = 0x15
+	opBalance        = 0x31
+	opCodeSize       = 0x38
+	opCodeCopy       = 0x39
+	opReturnDataSize = 0x3d
+	opReturnDataCopy = 0x3e
+	opPop            = 0x50
+	opMload          = 0x51
+	opMstore         = 0x52
+	opJump           = 0x56
+	opJumpi          = 0x57
+	opGas            = 0x5a
+	opJumpDest       = 0x5b
+	opPush1          = 0x60
+	opPush2          = 0x61
+	opDup1           = 0x80
+	opSwap1          = 0x90
+	opReturn         = 0xf3
+	opStaticCall     = 0xfa
+)
+
+// aggregatorCode 无部署聚合器的 initcode。
+//
+// 调用参数直接拼在 initcode 后面，每个子调用编码为：32 字节目标地址 + 32 字节 calldata 长度 + calldata。
+// 构造函数依次 STATICCALL 每个目标 (calldata 为空时改为读取目标的 BALANCE)，
+// 把 [success(32) | returndatasize(32) | returndata] 依次写入内存，最后作为“合约代码” RETURN。
+// eth_call 不带 to 时执行的就是这段构造函数，返回值就是聚合结果，链上不需要任何预先部署的合约。
+var aggregatorCode = buildAggregator()
+
+// asm 极简汇编器，只支持 PUSH2 形式的标签跳转
+type asm struct {
+	code   []byte
+	labels map[string]int
+	fixups map[int]string
+}
+
+func (a *asm) op(ops ...byte) *asm {
+	a.code = append(a.code, ops...)
+	return a
+}
+
+func (a *asm) push1(v byte) *asm {
+	return a.op(opPush1, v)
+}
+
+// dup / swap 的 n 从 1 开始，对应 DUP1 / SWAP1
+func (a *asm) dup(n byte) *asm  { return a.op(opDup1 + n - 1) }
+func (a *asm) swap(n byte) *asm { return a.op(opSwap1 + n - 1) }
+
+// pushLabel 压入标签地址，地址在 assemble 时回填
+func (a *asm) pushLabel(name string) *asm {
+	a.fixups[len(a.code)+1] = name
+	return a.op(opPush2, 0, 0)
+}
+
+func (a *asm) label(name string) *asm {
+	a.labels[name] = len(a.code)
+	return a.op(opJumpDest)
+}
+
+func (a *asm) assemble() []byte {
+	for pos, name := range a.fixups {
+		binary.BigEndian.PutUint16(a.code[pos:], uint16(a.labels[name]))
+	}
+	return a.code
+}
+
+func buildAggregator() []byte {
+	a := &asm{labels: map[string]int{}, fixups: map[int]string{}}
+	// 栈: [ptr, out]，ptr 是当前子调用在代码里的偏移，out 是输出写到的内存位置
+	a.pushLabel("payload").push1(0)
+	a.label("loop")
+	// ptr >= codesize 时结束
+	a.op(opCodeSize).dup(3).op(opLt, opIsZero).pushLabel("end").op(opJumpi)
+	// 读取目标地址和长度到 mem[out : out+64]
+	a.push1(64).dup(3).dup(3).op(opCodeCopy)
+	a.dup(1).push1(32).op(opAdd, opMload) // [ptr, out, len]
+	// calldata 拷到 mem[out+64:]，调用结束后这里会被返回数据覆盖
+	a.dup(1).dup(4).push1(64).op(opAdd).dup(4).push1(64).op(opAdd).op(opCodeCopy)
+	// ptr += 64 + len
+	a.dup(1).push1(64).op(opAdd).dup(4).op(opAdd).swap(3).op(opPop)
+	a.dup(2).op(opMload) // [ptr, out, len, target]
+	a.dup(2).op(opIsZero).pushLabel("balance").op(opJumpi)
+	// staticcall(gas, target, out+64, len, 0, 0)
+	a.push1(0).push1(0).dup(4).dup(6).push1(64).op(opAdd).dup(5).op(opGas, opStaticCall)
+	a.dup(4).op(opMstore, opPop, opPop) // mem[out] = success
+	a.op(opReturnDataSize).dup(2).push1(32).op(opAdd, opMstore)
+	a.op(opReturnDataSize).push1(0).dup(3).push1(64).op(opAdd, opReturnDataCopy)
+	a.op(opReturnDataSize).push1(64).op(opAdd, opAdd) // out += 64 + returndatasize
+	a.pushLabel("loop").op(opJump)
+
+	a.label("balance") // [ptr, out, len, target]
+	a.op(opBalance).dup(3).push1(64).op(opAdd, opMstore, opPop)
+	a.push1(1).dup(2).op(opMstore)
+	a.push1(32).dup(2).push1(32).op(opAdd, opMstore)
+	a.push1(96).op(opAdd)
+	a.pushLabel("loop").op(opJump)
+
+	a.label("end") // [ptr, out]
+	a.push1(0).op(opReturn)
+
+	// 调用参数紧跟在 initcode 之后
+	a.labels["payload"] = len(a.code)
+	return a.assemble()
+}
+
+// EncodeDeployless 把一组调用编码成可直接用于 eth_call data 的 initcode
+func EncodeDeployless(calls []core.Call) []byte {
+	data := append([]byte{}, aggregatorCode...)
+	for _, c := range calls {
+		data = append(data, common.LeftPadBytes(c.Target.Bytes(), 32)...)
+		data = append(data, common.LeftPadBytes(big.NewInt(int64(len(c.Data))).Bytes(), 32)...)
+		data = append(data, c.Data...)
+	}
+	return data
+}
+
+// DecodeDeployless 解析聚合器的返回结果
+func DecodeDeployless(out []byte, n int) ([]core.CallResult, error) {
+	results := make([]core.CallResult, 0, n)
+	for len(out) > 0 {
+		if len(out) < 64 {
+			return nil, errors.New("deployless: truncated result header")
+		}
+		size := new(big.Int).SetBytes(out[32:64])
+		if !size.IsUint64() || size.Uint64() > uint64(len(out)-64) {
+			return nil, errors.New("deployless: truncated return data")
+		}
+		end := 64 + int(size.Uint64())
+		results = append(results, core.CallResult{
+			Success:    out[31] == 1,
+			ReturnData: common.CopyBytes(out[64:end]),
+		})
+		out = out[end:]
+	}
+	if len(results) != n {
+		return nil, fmt.Errorf("deployless: expected %d results, got %d", n, len(results))
+	}
+	return results, nil
+}
+
+// DeploylessCaller 不依赖链上 Multicall3 的批量调用器，适用于任何 EVM 链
+type DeploylessCaller struct {
+	Client      *ethclient.Client
+	BlockNumber *big.Int // 固定查询的区块高度，nil 表示 latest
+	BatchSize   int
+	Context     context.Context // 取消或超时后停止调用，nil 表示 context.Background()
+}
+
+func NewDeploylessCaller(client *ethclient.Client) *DeploylessCaller {
+	return &DeploylessCaller{Client: client, BatchSize: DeploylessBatchSize}
+}
+
+// Aggregate 实现 core.BatchCaller。
+// 返回结果超过代码大小上限时自动对半拆分重试。
+func (d *DeploylessCaller) Aggregate(calls []core.Call) ([]core.CallResult, error) {
+	batchSize := d.BatchSize
+	if batchSize <= 0 {
+		batchSize = DeploylessBatchSize
+	}
+	var results []core.CallResult
+	for start := 0; start < len(calls); start += batchSize {
+		end := min(start+batchSize, len(calls))
+		part, err := d.aggregate(calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, part...)
+	}
+	return results, nil
+}
+
+func (d *DeploylessCaller) aggregate(calls []core.Call) ([]core.CallResult, error) {
+	if len(calls) == 0 {
+		return nil, nil
+	}
+	ctx := d.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out, err := d.Client.CallContract(ctx, ethereum.CallMsg{Data: EncodeDeployless(calls)}, d.BlockNumber)
+	if err != nil {
+		if len(calls) > 1 && isCodeSizeError(err) {
+			half := len(calls) / 2
+			left, err := d.aggregate(calls[:half])
+			if err != nil {
+				return nil, err
+			}
+			right, err := d.aggregate(calls[half:])
+			if err != nil {
+				return nil, err
+			}
+			return append(left, right...), nil
+		}
+		return nil, fmt.Errorf("deployless call failed: %w", err)
+	}
+	return DecodeDeployless(out, len(calls))
+}
+
+// Probe 发送一个不含子调用的聚合器，确认节点允许不带 to 的 eth_call
+func (d *DeploylessCaller) Probe() error {
+	_, err := d.Client.CallContract(context.Background(), ethereum.CallMsg{Data: aggregatorCode}, d.BlockNumber)
+	return err
+}
+
+// isCodeSizeError 判断是否因为返回结果过大 (超过代码大小上限或部署 gas 不足) 而失败
+func isCodeSizeError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"code size", "out of gas", "too large", "too big", "gas required exceeds"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package multicall
+
+import (
+	"bytes"
+	"chain-lens/core"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/holiman/uint256"
+)
+
+func TestDeploylessAggregator(t *testing.T) {
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		answer   = common.HexToAddress("0x00000000000000000000000000000000000a0001")
+		reverter = common.HexToAddress("0x00000000000000000000000000000000000a0002")
+		wallet   = common.HexToAddress("0x00000000000000000000000000000000000b0001")
+		identity = common.BytesToAddress([]byte{4}) // identity 预编译：原样返回输入
+	)
+	// mstore(0, 42); return(0, 32)
+	statedb.SetCode(answer, common.FromHex("602a60005260206000f3"), tracing.CodeChangeUnspecified)
+	// mstore(0, 7); revert(0, 32)
+	statedb.SetCode(reverter, common.FromHex("600760005260206000fd"), tracing.CodeChangeUnspecified)
+	statedb.SetBalance(wallet, uint256.NewInt(1e18), tracing.BalanceChangeUnspecified)
+
+	calls := []core.Call{
+		{Target: answer, Data: []byte{0x70, 0xa0, 0x82, 0x31}},
+		{Target: reverter, Data: []byte{0x01}},
+		{Target: wallet},
+		{Target: identity, Data: []byte("hello")},
+	}
+	out, _, _, err := runtime.Create(EncodeDeployless(calls), &runtime.Config{State: statedb, GasLimit: 10_000_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := DecodeDeployless(out, len(calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !results[0].Success || new(big.Int).SetBytes(results[0].ReturnData).Int64() != 42 {
+		t.Fatalf("answer: %+v", results[0])
+	}
+	if results[1].Success || new(big.Int).SetBytes(results[1].ReturnData).Int64() != 7 {
+		t.Fatalf("reverter: %+v", results[1])
+	}
+	if !results[2].Success || new(big.Int).SetBytes(results[2].ReturnData).Cmp(big.NewInt(1e18)) != 0 {
+		t.Fatalf("balance: %+v", results[2])
+	}
+	if !results[3].Success || !bytes.Equal(results[3].ReturnData, []byte("hello")) {
+		t.Fatalf("identity: %+v", results[3])
+	}
+}
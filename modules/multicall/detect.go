@@ -0,0 +1,79 @@
+package multicall
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/erc20"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// erc721InterfaceID ERC721 的 ERC165 接口 ID
+var erc721InterfaceID = [4]byte{0x80, 0xac, 0x58, 0xcd}
+
+// TokenInfo 代币类型和基础信息
+type TokenInfo struct {
+	Address     common.Address
+	Type        TokenType
+	Name        string
+	Symbol      string
+	Decimals    uint8
+	TotalSupply *big.Int // 原始值，未按精度换算；合约不支持时为 nil
+}
+
+// DetectToken 在一次批量调用里读取 supportsInterface / decimals / symbol / name / totalSupply，
+// 支持 ERC721 接口的识别为 ERC721，能读到 decimals 的识别为 ERC20。
+func DetectToken(caller core.BatchCaller, token common.Address) (*TokenInfo, error) {
+	parsed, err := erc20.TokenMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	supports := append(selector("supportsInterface(bytes4)"), common.RightPadBytes(erc721InterfaceID[:], 32)...)
+	calls := []core.Call{
+		{Target: token, Data: supports},
+		{Target: token, Data: selector("decimals()")},
+		{Target: token, Data: selector("symbol()")},
+		{Target: token, Data: selector("name()")},
+		{Target: token, Data: selector("totalSupply()")},
+	}
+	results, err := caller.Aggregate(calls)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(calls), len(results))
+	}
+
+	info := &TokenInfo{Address: token}
+	isWord := func(r core.CallResult) bool { return r.Success && len(r.ReturnData) >= 32 }
+	switch {
+	case isWord(results[0]) && new(big.Int).SetBytes(results[0].ReturnData[:32]).Sign() != 0:
+		info.Type = TokenTypeERC721
+	case isWord(results[1]):
+		info.Type = TokenTypeERC20
+		info.Decimals = uint8(new(big.Int).SetBytes(results[1].ReturnData[:32]).Uint64())
+	default:
+		return nil, errors.New("token " + token.Hex() + " is neither ERC20 nor ERC721")
+	}
+	info.Symbol = unpackString(parsed.Methods["symbol"].Outputs.Unpack, results[2])
+	info.Name = unpackString(parsed.Methods["name"].Outputs.Unpack, results[3])
+	if isWord(results[4]) {
+		info.TotalSupply = new(big.Int).SetBytes(results[4].ReturnData[:32])
+	}
+	return info, nil
+}
+
+// unpackString 解码返回 string 的调用，失败时返回空字符串
+func unpackString(unpack func([]byte) ([]any, error), r core.CallResult) string {
+	if !r.Success {
+		return ""
+	}
+	out, err := unpack(r.ReturnData)
+	if err != nil || len(out) == 0 {
+		return ""
+	}
+	s, _ := out[0].(string)
+	return s
+}
@@ -0,0 +1,162 @@
+package multicall
+
+import (
+	"chain-lens/core"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Strategy 批量查询的方式
+type Strategy string
+
+const (
+	StrategyAuto       Strategy = "auto"       // 按节点支持情况自动选择
+	StrategyMulticall  Strategy = "multicall"  // 链上 Multicall3 (没有部署时退回 deployless)
+	StrategyDeployless Strategy = "deployless" // 无部署 eth_call
+	StrategyRPCBatch   Strategy = "rpc-batch"  // JSON-RPC 批量请求
+	StrategySingle     Strategy = "single"     // 逐个请求
+)
+
+// ParseStrategy 解析配置里的 batch_strategy，空字符串视为 auto
+func ParseStrategy(s string) (Strategy, error) {
+	switch st := Strategy(s); st {
+	case "":
+		return StrategyAuto, nil
+	case StrategyAuto, StrategyMulticall, StrategyDeployless, StrategyRPCBatch, StrategySingle:
+		return st, nil
+	default:
+		return "", fmt.Errorf("unknown batch strategy: %s", s)
+	}
+}
+
+// resolveStrategy 第一次查询前确定实际使用的方式。
+// auto 的顺序：链上 Multicall3 → 无部署 eth_call → JSON-RPC 批量 → 逐个请求。
+func (m *MultiChecker) resolveStrategy() Strategy {
+	m.resolveOnce.Do(func() {
+		switch m.Strategy {
+		case "", StrategyAuto:
+			m.mode = m.detectStrategy()
+		case StrategyMulticall:
+			m.mode = StrategyMulticall
+			if !m.multicallDeployed() {
+				m.mode = StrategyDeployless
+			}
+		default:
+			m.mode = m.Strategy
+		}
+		if m.mode != StrategyMulticall {
+			core.LoggerOrDefault(m.Logger).Info("batching strategy selected", "strategy", m.mode, "chain", m.Chain.Name)
+		}
+	})
+	return m.mode
+}
+
+// AtBlock 返回固定在另一个区块上的副本。目标区块上 Multicall3 可用时沿用已确定的批量方式，
+// 避免每次都重新探测节点。
+func (m *MultiChecker) AtBlock(block *big.Int) *MultiChecker {
+	c := &MultiChecker{
+		Client:        m.Client,
+		Multicall:     m.Multicall,
+		MulticallAddr: m.MulticallAddr,
+		BlockNumber:   block,
+		Chain:         m.Chain,
+		Strategy:      m.Strategy,
+		Logger:        m.Logger,
+		OnBatch:       m.OnBatch,
+		Context:       m.Context,
+	}
+	if mode := m.resolveStrategy(); mode != StrategyMulticall || m.Chain.MulticallAvailableAt(block) {
+		c.mode = mode
+		c.resolveOnce.Do(func() {})
+	}
+	return c
+}
+
+func (m *MultiChecker) detectStrategy() Strategy {
+	if m.multicallDeployed() {
+		return StrategyMulticall
+	}
+	if m.deploylessCaller().Probe() == nil {
+		return StrategyDeployless
+	}
+	if core.SupportsRPCBatch(m.Client.Client()) {
+		return StrategyRPCBatch
+	}
+	return StrategySingle
+}
+
+// multicallDeployed 查询区块不早于部署区块，且 Multicall3 地址上有代码 (查询失败时假定已部署)
+func (m *MultiChecker) multicallDeployed() bool {
+	if !m.Chain.MulticallAvailableAt(m.BlockNumber) {
+		return false
+	}
+	code, err := m.Client.CodeAt(context.Background(), m.MulticallAddr, m.BlockNumber)
+	return err != nil || len(code) > 0
+}
+
+// caller 返回非 Multicall3 方式对应的 BatchCaller
+func (m *MultiChecker) caller(mode Strategy) core.BatchCaller {
+	switch mode {
+	case StrategyDeployless:
+		return m.deploylessCaller()
+	case StrategyRPCBatch:
+		b := core.NewRPCBatcher(m.Client.Client())
+		b.BlockNumber = m.BlockNumber
+		b.Context = m.Context
+		return b
+	default:
+		return &SingleCaller{Client: m.Client, BlockNumber: m.BlockNumber, Context: m.Context}
+	}
+}
+
+func (m *MultiChecker) deploylessCaller() *DeploylessCaller {
+	d := NewDeploylessCaller(m.Client)
+	d.BlockNumber = m.BlockNumber
+	d.Context = m.Context
+	return d
+}
+
+// WithContext 返回使用 ctx 的副本，ctx 取消或超时后批量调用不再继续等待节点
+func (m *MultiChecker) WithContext(ctx context.Context) *MultiChecker {
+	c := m.AtBlock(m.BlockNumber)
+	c.Context = ctx
+	return c
+}
+
+// SingleCaller 逐个发送 eth_call / eth_getBalance，任何节点都支持，但最慢
+type SingleCaller struct {
+	Client      *ethclient.Client
+	BlockNumber *big.Int
+	Context     context.Context // nil 表示 context.Background()
+}
+
+func (s *SingleCaller) Aggregate(calls []core.Call) ([]core.CallResult, error) {
+	ctx := s.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	results := make([]core.CallResult, len(calls))
+	for i, c := range calls {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(c.Data) == 0 {
+			balance, err := s.Client.BalanceAt(ctx, c.Target, s.BlockNumber)
+			if err == nil {
+				results[i] = core.CallResult{Success: true, ReturnData: common.LeftPadBytes(balance.Bytes(), 32)}
+			}
+			continue
+		}
+		target := c.Target
+		out, err := s.Client.CallContract(ctx, ethereum.CallMsg{To: &target, Data: c.Data}, s.BlockNumber)
+		if err == nil {
+			results[i] = core.CallResult{Success: true, ReturnData: out}
+		}
+	}
+	return results, nil
+}
@@ -4,30 +4,48 @@ import (
 	"chain-lens/core"
 	"chain-lens/tools"
 	"context"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type Checker struct {
-	EvmClient *ethclient.Client
+	EvmClient   *ethclient.Client
+	BlockNumber *big.Int        // 固定查询的区块高度，nil 表示 latest
+	Context     context.Context // 逐个查询使用的 ctx，nil 表示 context.Background()
+	Symbol      string
+	Decimals    uint8
+	Logger      core.Logger // 诊断日志，默认沿用 EvmClient 的日志器
 }
 
 func NewChecker(evmClient *core.EvmClient) (*Checker, error) {
+	chain := evmClient.Chain
+	if chain.NativeSymbol == "" {
+		chain = core.LookupChain(evmClient.ChainID, nil)
+	}
 	return &Checker{
 		EvmClient: evmClient.Client,
+		Symbol:    chain.NativeSymbol,
+		Decimals:  chain.NativeDecimals,
+		Logger:    evmClient.Log(),
 	}, nil
 }
 
 // BalanceOf CheckBalance 查ETH余额的工具函数
 func (c *Checker) BalanceOf(address common.Address) (*core.TokenBalance, error) {
-	weiBalance, err := c.EvmClient.BalanceAt(context.Background(), address, nil)
+	ctx := c.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	weiBalance, err := c.EvmClient.BalanceAt(ctx, address, c.BlockNumber)
 	if err != nil {
+		core.LoggerOrDefault(c.Logger).Debug("native balance failed", "wallet", address, "err", err)
 		return nil, err
 	}
-	ethValue := tools.WeiToEther(weiBalance, 18)
+	ethValue := tools.WeiToEther(weiBalance, c.Decimals)
 	return &core.TokenBalance{
-		Symbol:       "ETH",
+		Symbol:       c.Symbol,
 		Balance:      ethValue,
 		Owner:        address,
 		TokenAddress: address,
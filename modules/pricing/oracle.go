@@ -0,0 +1,322 @@
+package pricing
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/erc20"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	SourceChainlink = "chainlink"
+	SourceUniswapV2 = "uniswap_v2"
+	SourceUniswapV3 = "uniswap_v3"
+	SourceFixed     = "fixed"
+
+	DefaultMaxAge = 24 * time.Hour // Chainlink 喂价默认最长允许的更新间隔
+	maxRouteDepth = 4              // 报价路由最多嵌套几层 (token → quote → quote ...)
+	pricePrec     = 256
+)
+
+// Route 配置里的一条报价路由，说明某个代币的美元价格从哪里来：
+//
+//	{"token": "0xA0b8...", "source": "chainlink", "feed": "0x8fFf...", "max_age": "24h"}
+//	{"token": "0xTKN...", "source": "uniswap_v2", "pool": "0xPair...", "quote": "0xA0b8..."}
+//	{"token": "0xTKN...", "source": "uniswap_v3", "pool": "0xPool...", "quote": "0xC02a..."}
+//	{"token": "0xA0b8...", "source": "fixed", "price": "1"}
+//
+// token 填 "native" 表示链的原生币。Uniswap 路由先得到以 quote 计价的价格，
+// 再递归查 quote 自己的路由换算成美元。
+type Route struct {
+	Token  string `json:"token"`
+	Source string `json:"source"`
+	Feed   string `json:"feed,omitempty"`    // chainlink: 聚合器地址
+	Pool   string `json:"pool,omitempty"`    // uniswap_v2 / uniswap_v3: 池子地址
+	Quote  string `json:"quote,omitempty"`   // uniswap: 计价代币地址
+	Price  string `json:"price,omitempty"`   // fixed: 固定价格
+	MaxAge string `json:"max_age,omitempty"` // chainlink: 超过这个时间没更新视为过期，例如 "1h"
+}
+
+// Quote 某个代币的美元报价
+type Quote struct {
+	Token     common.Address `json:"token"`
+	USD       *big.Float     `json:"usd"`
+	Source    string         `json:"source"`
+	UpdatedAt time.Time      `json:"updated_at,omitempty"` // 仅 chainlink 有
+}
+
+// Oracle 按路由读取链上价格。所有调用都经过 Caller (通常是固定了区块的 MultiChecker)，
+// 保证价格和余额来自同一个区块。
+type Oracle struct {
+	Caller    core.BatchCaller
+	BlockTime time.Time // 固定区块的时间戳，用于判断 Chainlink 喂价是否过期
+	routes    map[common.Address]Route
+	cache     map[common.Address]*Quote
+}
+
+func NewOracle(caller core.BatchCaller, blockTime time.Time, routes []Route) (*Oracle, error) {
+	o := &Oracle{
+		Caller:    caller,
+		BlockTime: blockTime,
+		routes:    make(map[common.Address]Route, len(routes)),
+		cache:     make(map[common.Address]*Quote),
+	}
+	for _, r := range routes {
+		token, err := ParseToken(r.Token)
+		if err != nil {
+			return nil, err
+		}
+		switch r.Source {
+		case SourceChainlink, SourceUniswapV2, SourceUniswapV3, SourceFixed:
+		default:
+			return nil, fmt.Errorf("price route for %s: unknown source %q", r.Token, r.Source)
+		}
+		o.routes[token] = r
+	}
+	return o, nil
+}
+
+// ParseToken 解析路由里的代币地址，"native" 或空字符串表示原生币 (零地址)
+func ParseToken(s string) (common.Address, error) {
+	if s == "" || strings.EqualFold(s, "native") {
+		return common.Address{}, nil
+	}
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid token address %q in price route", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// Price 返回代币的美元价格
+func (o *Oracle) Price(token common.Address) (*Quote, error) {
+	return o.price(token, 0)
+}
+
+func (o *Oracle) price(token common.Address, depth int) (*Quote, error) {
+	if q, ok := o.cache[token]; ok {
+		return q, nil
+	}
+	if depth > maxRouteDepth {
+		return nil, fmt.Errorf("price route for %s is too deep (cycle?)", token.Hex())
+	}
+	route, ok := o.routes[token]
+	if !ok {
+		return nil, fmt.Errorf("no price route configured for %s", token.Hex())
+	}
+
+	var (
+		q   *Quote
+		err error
+	)
+	switch route.Source {
+	case SourceFixed:
+		usd, ok := new(big.Float).SetPrec(pricePrec).SetString(route.Price)
+		if !ok {
+			return nil, fmt.Errorf("invalid fixed price %q for %s", route.Price, token.Hex())
+		}
+		q = &Quote{Token: token, USD: usd, Source: SourceFixed}
+	case SourceChainlink:
+		q, err = o.chainlink(token, route)
+	case SourceUniswapV2, SourceUniswapV3:
+		q, err = o.uniswap(token, route, depth)
+	}
+	if err != nil {
+		return nil, err
+	}
+	o.cache[token] = q
+	return q, nil
+}
+
+// chainlink 读取 latestRoundData，并检查喂价是否过期
+func (o *Oracle) chainlink(token common.Address, route Route) (*Quote, error) {
+	if !common.IsHexAddress(route.Feed) {
+		return nil, fmt.Errorf("chainlink route for %s needs a feed address", token.Hex())
+	}
+	maxAge := DefaultMaxAge
+	if route.MaxAge != "" {
+		d, err := time.ParseDuration(route.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_age %q: %w", route.MaxAge, err)
+		}
+		maxAge = d
+	}
+	parsed, err := AggregatorMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	feed := common.HexToAddress(route.Feed)
+	out, err := o.call(parsed, feed, "latestRoundData", "decimals")
+	if err != nil {
+		return nil, fmt.Errorf("chainlink feed %s: %w", feed.Hex(), err)
+	}
+	answer := out[0][1].(*big.Int)
+	updatedAt := time.Unix(out[0][3].(*big.Int).Int64(), 0)
+	decimals := out[1][0].(uint8)
+
+	if answer.Sign() <= 0 {
+		return nil, fmt.Errorf("chainlink feed %s returned non-positive answer %s", feed.Hex(), answer)
+	}
+	if age := o.BlockTime.Sub(updatedAt); !o.BlockTime.IsZero() && age > maxAge {
+		return nil, fmt.Errorf("chainlink feed %s is stale: updated %s ago (max %s)", feed.Hex(), age.Round(time.Second), maxAge)
+	}
+	return &Quote{
+		Token:     token,
+		USD:       scale(answer, int(decimals)),
+		Source:    SourceChainlink,
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+// uniswap 从 V2 储备量或 V3 slot0 算出以 quote 计价的价格，再乘以 quote 的美元价格
+func (o *Oracle) uniswap(token common.Address, route Route, depth int) (*Quote, error) {
+	if !common.IsHexAddress(route.Pool) || !common.IsHexAddress(route.Quote) {
+		return nil, fmt.Errorf("%s route for %s needs pool and quote addresses", route.Source, token.Hex())
+	}
+	pool := common.HexToAddress(route.Pool)
+	quoteToken := common.HexToAddress(route.Quote)
+
+	metaData := PairV2MetaData
+	priceMethod := "getReserves"
+	if route.Source == SourceUniswapV3 {
+		metaData = PoolV3MetaData
+		priceMethod = "slot0"
+	}
+	parsed, err := metaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	out, err := o.call(parsed, pool, priceMethod, "token0", "token1")
+	if err != nil {
+		return nil, fmt.Errorf("%s pool %s: %w", route.Source, pool.Hex(), err)
+	}
+	token0 := out[1][0].(common.Address)
+	token1 := out[2][0].(common.Address)
+	if !((token0 == token && token1 == quoteToken) || (token0 == quoteToken && token1 == token)) {
+		return nil, fmt.Errorf("pool %s is not a %s/%s pool", pool.Hex(), token.Hex(), quoteToken.Hex())
+	}
+	dec0, dec1, err := o.decimalsPair(token0, token1)
+	if err != nil {
+		return nil, err
+	}
+
+	// price1Per0: 1 个 token0 值多少个 token1 (已按 decimals 换算)
+	var price1Per0 *big.Float
+	if route.Source == SourceUniswapV2 {
+		reserve0, reserve1 := out[0][0].(*big.Int), out[0][1].(*big.Int)
+		if reserve0.Sign() == 0 || reserve1.Sign() == 0 {
+			return nil, fmt.Errorf("pool %s has no liquidity", pool.Hex())
+		}
+		price1Per0 = newFloat().Quo(scale(reserve1, dec1), scale(reserve0, dec0))
+	} else {
+		price1Per0 = SqrtPriceX96ToPrice(out[0][0].(*big.Int), dec0, dec1)
+		if price1Per0.Sign() == 0 {
+			return nil, fmt.Errorf("pool %s has zero price", pool.Hex())
+		}
+	}
+
+	priceInQuote := price1Per0
+	if token1 == token {
+		priceInQuote = newFloat().Quo(newFloat().SetInt64(1), price1Per0)
+	}
+
+	quoteUSD, err := o.price(quoteToken, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("price of quote token: %w", err)
+	}
+	return &Quote{
+		Token:  token,
+		USD:    newFloat().Mul(priceInQuote, quoteUSD.USD),
+		Source: route.Source,
+	}, nil
+}
+
+// SqrtPriceX96ToPrice 把 V3 的 sqrtPriceX96 换算成 1 个 token0 值多少个 token1
+// price = (sqrtPriceX96 / 2^96)^2 * 10^(dec0 - dec1)
+func SqrtPriceX96ToPrice(sqrtPriceX96 *big.Int, dec0, dec1 int) *big.Float {
+	ratio := newFloat().Quo(newFloat().SetInt(sqrtPriceX96), newFloat().SetMantExp(big.NewFloat(1), 96))
+	price := newFloat().Mul(ratio, ratio)
+	exp := dec0 - dec1
+	factor := newFloat().SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil))
+	if exp >= 0 {
+		return price.Mul(price, factor)
+	}
+	return price.Quo(price, factor)
+}
+
+// decimalsPair 一次 Multicall 查询两个代币的 decimals
+func (o *Oracle) decimalsPair(token0, token1 common.Address) (int, int, error) {
+	parsed, err := erc20.TokenMetaData.GetAbi()
+	if err != nil {
+		return 0, 0, err
+	}
+	data, _ := parsed.Pack("decimals")
+	results, err := o.Caller.Aggregate([]core.Call{{Target: token0, Data: data}, {Target: token1, Data: data}})
+	if err != nil {
+		return 0, 0, err
+	}
+	var decs [2]int
+	for i, res := range results {
+		if !res.Success {
+			return 0, 0, errors.New("failed to get pool token decimals")
+		}
+		out, err := parsed.Unpack("decimals", res.ReturnData)
+		if err != nil || len(out) == 0 {
+			return 0, 0, errors.New("failed to decode pool token decimals")
+		}
+		d, _ := out[0].(uint8)
+		decs[i] = int(d)
+	}
+	return decs[0], decs[1], nil
+}
+
+// call 在一次 Multicall 里调用同一个合约的多个无参函数，返回每个函数解码后的输出
+func (o *Oracle) call(parsed *abi.ABI, target common.Address, methods ...string) ([][]any, error) {
+	calls := make([]core.Call, 0, len(methods))
+	for _, m := range methods {
+		data, err := parsed.Pack(m)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, core.Call{Target: target, Data: data})
+	}
+	results, err := o.Caller.Aggregate(calls)
+	if err != nil {
+		return nil, err
+	}
+	outs := make([][]any, len(methods))
+	for i, res := range results {
+		if !res.Success {
+			return nil, fmt.Errorf("%s reverted", methods[i])
+		}
+		out, err := parsed.Unpack(methods[i], res.ReturnData)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", methods[i], err)
+		}
+		outs[i] = out
+	}
+	return outs, nil
+}
+
+// scale 把整数按 decimals 换算成小数
+func scale(v *big.Int, decimals int) *big.Float {
+	f := newFloat().SetInt(v)
+	divisor := newFloat().SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return f.Quo(f, divisor)
+}
+
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(pricePrec)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
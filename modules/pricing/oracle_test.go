@@ -0,0 +1,109 @@
+package pricing
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/erc20"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeChain 按 (合约地址, 函数选择器) 返回预设的 ABI 编码结果
+type fakeChain struct {
+	t       *testing.T
+	answers map[string][]byte
+}
+
+func (f *fakeChain) set(target common.Address, md *bind.MetaData, method string, values ...any) {
+	parsed, err := md.GetAbi()
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	out, err := parsed.Methods[method].Outputs.Pack(values...)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.answers[target.Hex()+string(parsed.Methods[method].ID)] = out
+}
+
+func (f *fakeChain) Aggregate(calls []core.Call) ([]core.CallResult, error) {
+	var results []core.CallResult
+	for _, c := range calls {
+		out, ok := f.answers[c.Target.Hex()+string(c.Data[:4])]
+		results = append(results, core.CallResult{Success: ok, ReturnData: out})
+	}
+	return results, nil
+}
+
+func TestOracleRoutes(t *testing.T) {
+	var (
+		usdc    = common.HexToAddress("0x0000000000000000000000000000000000000a01")
+		weth    = common.HexToAddress("0x0000000000000000000000000000000000000a02")
+		tkn     = common.HexToAddress("0x0000000000000000000000000000000000000a03")
+		usdFeed = common.HexToAddress("0x0000000000000000000000000000000000000f01")
+		pairV2  = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+		poolV3  = common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	)
+	blockTime := time.Unix(1_700_000_000, 0)
+	f := &fakeChain{t: t, answers: map[string][]byte{}}
+
+	// USDC = 1.0001 USD (8 位小数)，10 分钟前更新
+	f.set(usdFeed, AggregatorMetaData, "latestRoundData",
+		big.NewInt(1), big.NewInt(100010000), big.NewInt(0), big.NewInt(blockTime.Unix()-600), big.NewInt(1))
+	f.set(usdFeed, AggregatorMetaData, "decimals", uint8(8))
+	f.set(usdc, erc20.TokenMetaData, "decimals", uint8(6))
+	f.set(weth, erc20.TokenMetaData, "decimals", uint8(18))
+	f.set(tkn, erc20.TokenMetaData, "decimals", uint8(18))
+
+	// V2: token0 = USDC, token1 = WETH，1 WETH = 2000 USDC
+	f.set(pairV2, PairV2MetaData, "getReserves",
+		new(big.Int).Mul(big.NewInt(2_000_000), big.NewInt(1_000_000)), // 2,000,000 USDC
+		new(big.Int).Mul(big.NewInt(1_000), big.NewInt(1e18)),          // 1,000 WETH
+		uint32(0))
+	f.set(pairV2, PairV2MetaData, "token0", usdc)
+	f.set(pairV2, PairV2MetaData, "token1", weth)
+
+	// V3: token0 = WETH，token1 = TKN，1 WETH = 4 TKN -> sqrtPriceX96 = 2 * 2^96
+	f.set(poolV3, PoolV3MetaData, "slot0",
+		new(big.Int).Lsh(big.NewInt(2), 96), big.NewInt(0), uint16(0), uint16(0), uint16(0), uint8(0), true)
+	f.set(poolV3, PoolV3MetaData, "token0", weth)
+	f.set(poolV3, PoolV3MetaData, "token1", tkn)
+
+	routes := []Route{
+		{Token: usdc.Hex(), Source: SourceChainlink, Feed: usdFeed.Hex(), MaxAge: "1h"},
+		{Token: weth.Hex(), Source: SourceUniswapV2, Pool: pairV2.Hex(), Quote: usdc.Hex()},
+		{Token: tkn.Hex(), Source: SourceUniswapV3, Pool: poolV3.Hex(), Quote: weth.Hex()},
+		{Token: "native", Source: SourceFixed, Price: "3000"},
+	}
+	o, err := NewOracle(f, blockTime, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[common.Address]float64{
+		usdc:             1.0001,
+		weth:             2000 * 1.0001,
+		tkn:              2000 * 1.0001 / 4,
+		common.Address{}: 3000,
+	}
+	for token, want := range cases {
+		q, err := o.Price(token)
+		if err != nil {
+			t.Fatalf("price %s: %v", token.Hex(), err)
+		}
+		got, _ := q.USD.Float64()
+		if diff := got - want; diff > 1e-6 || diff < -1e-6 {
+			t.Fatalf("price %s: want %f, got %f", token.Hex(), want, got)
+		}
+	}
+
+	// 喂价超过 max_age 视为过期
+	stale, _ := NewOracle(f, blockTime.Add(2*time.Hour), routes[:1])
+	if _, err := stale.Price(usdc); err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Fatalf("expected stale error, got %v", err)
+	}
+}
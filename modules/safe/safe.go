@@ -0,0 +1,86 @@
+package safe
+
+import (
+	"chain-lens/core"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SafeMetaData Safe (Gnosis Safe) 合约里用到的只读函数
+var SafeMetaData = &bind.MetaData{
+	ABI: `[{"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"VERSION","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"masterCopy","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}]`,
+}
+
+// methods 每个地址批量调用的函数，顺序对应 Info 的解码顺序
+var methods = []string{"getOwners", "getThreshold", "nonce", "VERSION", "masterCopy"}
+
+// Info 单个地址的 Safe 检测结果
+type Info struct {
+	Address   common.Address   `json:"address"`
+	IsSafe    bool             `json:"is_safe"`
+	Owners    []common.Address `json:"owners,omitempty"`
+	Threshold uint64           `json:"threshold,omitempty"`
+	Nonce     uint64           `json:"nonce,omitempty"`     // 已执行的 Safe 交易数
+	Version   string           `json:"version,omitempty"`   // 例如 "1.3.0"
+	Singleton *common.Address  `json:"singleton,omitempty"` // SafeProxy 指向的实现合约，非代理部署时为空
+}
+
+// Detect 在一次批量调用里查询 getOwners / getThreshold / nonce / VERSION / masterCopy 判断地址是否为 Safe。
+// getOwners 和 getThreshold 都成功、owners 非空且 1 <= threshold <= owners 数量时视为 Safe；
+// SafeProxy 会在 fallback 之前拦截 masterCopy()，能读到实现合约地址说明是代理部署。
+func Detect(caller core.BatchCaller, addrs []common.Address, batchSize int) ([]Info, error) {
+	parsed, err := SafeMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	calls := make([]core.Call, 0, len(addrs)*len(methods))
+	for _, addr := range addrs {
+		for _, method := range methods {
+			calls = append(calls, core.Call{Target: addr, Data: parsed.Methods[method].ID})
+		}
+	}
+	results, err := core.AggregateInBatches(caller, calls, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("safe detect failed: %w", err)
+	}
+
+	infos := make([]Info, len(addrs))
+	for i, addr := range addrs {
+		infos[i].Address = addr
+		res := results[i*len(methods) : (i+1)*len(methods)]
+		ownersRes, thresholdRes := res[0], res[1]
+		if !ownersRes.Success || !thresholdRes.Success || len(thresholdRes.ReturnData) != 32 {
+			continue
+		}
+		out, err := parsed.Unpack("getOwners", ownersRes.ReturnData)
+		if err != nil || len(out) == 0 {
+			continue
+		}
+		owners, ok := out[0].([]common.Address)
+		threshold := new(big.Int).SetBytes(thresholdRes.ReturnData)
+		if !ok || len(owners) == 0 || threshold.Sign() == 0 || threshold.Cmp(big.NewInt(int64(len(owners)))) > 0 {
+			continue
+		}
+		info := &infos[i]
+		info.IsSafe = true
+		info.Owners = owners
+		info.Threshold = threshold.Uint64()
+		if res[2].Success && len(res[2].ReturnData) == 32 {
+			info.Nonce = new(big.Int).SetBytes(res[2].ReturnData).Uint64()
+		}
+		if res[3].Success {
+			if out, err := parsed.Unpack("VERSION", res[3].ReturnData); err == nil && len(out) > 0 {
+				info.Version, _ = out[0].(string)
+			}
+		}
+		if res[4].Success && len(res[4].ReturnData) == 32 {
+			if singleton := common.BytesToAddress(res[4].ReturnData); singleton != (common.Address{}) {
+				info.Singleton = &singleton
+			}
+		}
+	}
+	return infos, nil
+}
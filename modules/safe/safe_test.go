@@ -0,0 +1,78 @@
+package safe
+
+import (
+	"chain-lens/core"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var singleton = common.HexToAddress("0xd9db270c1b5e3bd161e8c8503c55ceabee709552")
+
+// fakeSafe 按目标地址返回预设的 getOwners / getThreshold 结果
+type fakeSafe struct {
+	owners    map[common.Address][]common.Address
+	threshold map[common.Address]int64
+	versions  map[common.Address]string
+}
+
+func (f *fakeSafe) Aggregate(calls []core.Call) ([]core.CallResult, error) {
+	parsed, _ := SafeMetaData.GetAbi()
+	results := make([]core.CallResult, len(calls))
+	for i, c := range calls {
+		switch string(c.Data[:4]) {
+		case string(parsed.Methods["getOwners"].ID):
+			if owners, ok := f.owners[c.Target]; ok {
+				out, _ := parsed.Methods["getOwners"].Outputs.Pack(owners)
+				results[i] = core.CallResult{Success: true, ReturnData: out}
+			}
+		case string(parsed.Methods["getThreshold"].ID):
+			if t, ok := f.threshold[c.Target]; ok {
+				results[i] = core.CallResult{Success: true, ReturnData: common.LeftPadBytes(big.NewInt(t).Bytes(), 32)}
+			}
+		case string(parsed.Methods["nonce"].ID):
+			if _, ok := f.versions[c.Target]; ok {
+				results[i] = core.CallResult{Success: true, ReturnData: common.LeftPadBytes(big.NewInt(17).Bytes(), 32)}
+			}
+		case string(parsed.Methods["VERSION"].ID):
+			if v, ok := f.versions[c.Target]; ok {
+				out, _ := parsed.Methods["VERSION"].Outputs.Pack(v)
+				results[i] = core.CallResult{Success: true, ReturnData: out}
+			}
+		case string(parsed.Methods["masterCopy"].ID):
+			if _, ok := f.versions[c.Target]; ok {
+				results[i] = core.CallResult{Success: true, ReturnData: common.LeftPadBytes(singleton.Bytes(), 32)}
+			}
+		}
+	}
+	return results, nil
+}
+
+func TestDetect(t *testing.T) {
+	var (
+		safe   = common.HexToAddress("0x5afe")
+		broken = common.HexToAddress("0xbad")
+		eoa    = common.HexToAddress("0xe0a")
+		owner1 = common.HexToAddress("0x01")
+		owner2 = common.HexToAddress("0x02")
+	)
+	f := &fakeSafe{
+		owners:    map[common.Address][]common.Address{safe: {owner1, owner2}, broken: {owner1}},
+		threshold: map[common.Address]int64{safe: 2, broken: 3},
+		versions:  map[common.Address]string{safe: "1.3.0"},
+	}
+	infos, err := Detect(f, []common.Address{safe, broken, eoa}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !infos[0].IsSafe || infos[0].Threshold != 2 || len(infos[0].Owners) != 2 {
+		t.Fatalf("expected safe 2/2, got %+v", infos[0])
+	}
+	if infos[0].Version != "1.3.0" || infos[0].Nonce != 17 || infos[0].Singleton == nil || *infos[0].Singleton != singleton {
+		t.Fatalf("unexpected safe details: %+v", infos[0])
+	}
+	if infos[1].IsSafe || infos[2].IsSafe {
+		t.Fatalf("unexpected safe detection: %+v %+v", infos[1], infos[2])
+	}
+}
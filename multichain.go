@@ -0,0 +1,186 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/modules/multicall"
+	"chain-lens/report"
+	"chain-lens/scanner"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NetworkConfig 多链模式下一条链的配置
+type NetworkConfig struct {
+	Name    string        `json:"name"`     // 报告里显示的名字，为空时用链注册表里的名字
+	RpcURLs []string      `json:"rpc_urls"` // 多个 RPC 组成连接池，轮询使用
+	Assets  []AssetConfig `json:"assets"`
+}
+
+// AssetConfig 一条链上要查询的资产
+type AssetConfig struct {
+	TokenAddress string `json:"token_address"`
+	TokenType    string `json:"token_type"`
+	Symbol       string `json:"symbol,omitempty"` // 可选：跨链汇总时的归并名，默认按链上的 symbol + 合约地址区分
+}
+
+// RunMultiChain 在配置的所有链上并发查询同一组钱包，输出各链合计和跨链合计
+func RunMultiChain(cfg Config, addresses []common.Address) {
+	// 提前校验资产类型，避免跑到一半才因为配置错误退出
+	for _, network := range cfg.Networks {
+		for _, asset := range network.Assets {
+			if _, err := ParseTokenType(asset.TokenType); err != nil {
+				fatal(i18n.Errorf("err.network_config", network.Name, err))
+			}
+		}
+	}
+	logger.Info("wallet addresses loaded", "count", len(addresses))
+	logger.Info("scanning networks", "count", len(cfg.Networks))
+	startTime := time.Now()
+
+	chains := make([]report.ChainReport, len(cfg.Networks))
+	var wg sync.WaitGroup
+	for i, network := range cfg.Networks {
+		wg.Add(1)
+		go func(i int, network NetworkConfig) {
+			defer wg.Done()
+			chains[i] = scanNetwork(cfg, network, addresses)
+		}(i, network)
+	}
+	wg.Wait()
+
+	rep := report.NewMultiChainReport(chains)
+	printMultiChain(rep)
+	fmt.Println(i18n.T("report.multichain.time", time.Since(startTime)))
+
+	if cfg.Output != "" {
+		if err := report.WriteJSON(cfg.Output, rep); err != nil {
+			logger.Warn("failed to write report", "path", cfg.Output, "err", err)
+		} else {
+			logger.Info("report written", "path", cfg.Output)
+		}
+	}
+}
+
+// scanNetwork 连接一条链的 RPC 池，固定区块后并发查询所有资产
+func scanNetwork(cfg Config, network NetworkConfig, addresses []common.Address) report.ChainReport {
+	cr := report.ChainReport{Network: network.Name}
+	pool, err := core.NewClientPool(network.RpcURLs)
+	if err != nil {
+		logger.Error("network connect failed", "network", network.Name, "err", err)
+		cr.Error = err.Error()
+		return cr
+	}
+	defer pool.Close()
+	chain := core.LookupChain(pool.ChainID, cfg.Chains)
+	pool.SetChain(chain)
+	cr.ChainID = chain.ChainID
+	if cr.Network == "" {
+		cr.Network = chain.Name
+	}
+
+	blockNumber, err := pool.Next().Client.BlockNumber(context.Background())
+	if err != nil {
+		logger.Error("failed to get block number", "network", cr.Network, "err", err)
+		cr.Error = err.Error()
+		return cr
+	}
+	cr.Block = blockNumber
+	block := new(big.Int).SetUint64(blockNumber)
+	logger.Info("network connected", "network", cr.Network, "chain_id", cr.ChainID, "block", blockNumber, "rpc", len(pool.Clients))
+
+	// 所有资产交给同一个 Scanner，批次在连接池的各个节点上并发执行
+	assets := make([]scanner.Asset, len(network.Assets))
+	for i, asset := range network.Assets {
+		tokenType, _ := ParseTokenType(asset.TokenType) // 已在 RunMultiChain 开头校验
+		assets[i] = scanner.Asset{Type: tokenType, Address: common.HexToAddress(asset.TokenAddress)}
+	}
+	strategy, err := multicall.ParseStrategy(cfg.Strategy)
+	if err != nil {
+		cr.Error = err.Error()
+		return cr
+	}
+	s, err := scanner.New(scanner.Options{Pool: pool, Assets: assets, Block: block, Strategy: strategy})
+	if err != nil {
+		cr.Error = err.Error()
+		return cr
+	}
+	results, err := s.Scan(context.Background(), addresses)
+	if err != nil {
+		cr.Error = err.Error()
+		return cr
+	}
+	cr.Assets = make([]*report.Snapshot, len(results))
+	for i, r := range results {
+		snap := report.NewSnapshot(cr.ChainID, blockNumber, r.Asset.Address, r.Balances)
+		if network.Assets[i].Symbol != "" {
+			snap.Symbol = network.Assets[i].Symbol
+			snap.Asset = network.Assets[i].Symbol
+		}
+		cr.Assets[i] = snap
+	}
+	return cr
+}
+
+func printMultiChain(rep *report.MultiChainReport) {
+	fmt.Printf("\n--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.multichain.title"))
+	fmt.Printf("--------------------------------------------------\n")
+	for _, chain := range rep.Chains {
+		if chain.Error != "" {
+			fmt.Printf("❌ %s: %s\n", chain.Network, chain.Error)
+			continue
+		}
+		fmt.Println(i18n.T("report.multichain.chain", chain.Network, chain.ChainID, chain.Block))
+		for _, snap := range chain.Assets {
+			total := new(big.Float)
+			failed := 0
+			for _, e := range snap.Balances {
+				if !e.Success {
+					failed++
+					continue
+				}
+				total.Add(total, report.ParseBalance(e.Balance))
+			}
+			line := fmt.Sprintf("   💰 %-8s %.4f", snap.Symbol, total)
+			if failed > 0 {
+				line += i18n.T("report.multichain.failed", failed)
+			}
+			fmt.Println(line)
+		}
+	}
+
+	fmt.Printf("--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.multichain.totals"))
+	for _, t := range rep.Totals {
+		networks := make([]string, 0, len(t.PerChain))
+		for network := range t.PerChain {
+			networks = append(networks, network)
+		}
+		sort.Strings(networks)
+		parts := make([]string, 0, len(networks))
+		for _, network := range networks {
+			parts = append(parts, fmt.Sprintf("%s %.4f", network, t.PerChain[network]))
+		}
+		fmt.Printf("   %-8s %.4f = %s\n", t.Asset, t.Total, strings.Join(parts, " + "))
+	}
+
+	fmt.Printf("--------------------------------------------------\n")
+	for _, w := range rep.Wallets {
+		line := "👤 " + w.Owner.Hex()
+		for _, t := range rep.Totals {
+			if b, ok := w.Balances[t.Asset]; ok {
+				line += fmt.Sprintf(" | %s %.4f", t.Asset, b)
+			}
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("--------------------------------------------------\n")
+}
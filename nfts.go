@@ -0,0 +1,176 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/modules/erc721"
+	"chain-lens/modules/multicall"
+	"chain-lens/report"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// nftReport nfts 子命令输出的 JSON 结构
+type nftReport struct {
+	ChainID      int64                `json:"chain_id"`
+	Block        uint64               `json:"block"`
+	TokenAddress common.Address       `json:"token_address"`
+	Owners       []erc721.OwnedTokens `json:"owners"`
+}
+
+// runNFTs nfts 子命令：列出每个钱包持有的具体 ERC721 Token ID
+//
+//	chain-lens nfts -file wallets.txt [-from 18000000] [-out nfts.json]
+func runNFTs(args []string) {
+	fs := flag.NewFlagSet("nfts", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	filePath := fs.String("file", "wallets.txt", "包含钱包地址的文件路径 (每行一个)")
+	fromBlock := fs.Uint64("from", 0, "合约不支持 Enumerable 时，扫描 Transfer 日志的起始区块")
+	chunk := fs.Uint64("chunk", core.DefaultLogChunk, "扫描日志的初始分段大小")
+	batch := fs.Int("batch", erc721.DefaultBatchSize, "每次 Multicall 打包的调用数")
+	outPath := fs.String("out", "", "把结果写成 JSON 文件")
+	withMetadata := fs.Bool("metadata", false, "通过 tokenURI 拉取并解析每个 NFT 的元数据")
+	gateway := fs.String("gateway", "", "解析 ipfs:// 使用的网关 (默认取配置 ipfs_gateway 或 "+erc721.DefaultIPFSGateway+")")
+	cacheDir := fs.String("cache-dir", ".nft-cache", "元数据 JSON 本地缓存目录 (为空则不缓存)")
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	cfg := loadConfig(*configPath)
+	if *gateway == "" {
+		*gateway = cfg.IPFSGateway
+	}
+	tokenType, err := ParseTokenType(cfg.TokenType)
+	if err != nil {
+		fatal(err)
+	}
+	if tokenType != multicall.TokenTypeERC721 {
+		fatal(i18n.Errorf("err.nfts_erc721_only"))
+	}
+	addresses, err := loadAddresses(*filePath)
+	if err != nil {
+		fatal(i18n.Errorf("err.read_file", err))
+	}
+	logger.Info("wallet addresses loaded", "count", len(addresses))
+
+	client, err := connect(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	defer client.Close()
+
+	startTime := time.Now()
+	blockNumber, err := client.Client.BlockNumber(context.Background())
+	if err != nil {
+		fatal(i18n.Errorf("err.block_number", err))
+	}
+	mc, err := newMultiChecker(cfg, client, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		fatal(err)
+	}
+
+	tokenAddr := common.HexToAddress(cfg.TokenAddress)
+	enumerator := erc721.NewEnumerator(tokenAddr, client, mc)
+	enumerator.BatchSize = *batch
+	enumerator.FromBlock = *fromBlock
+	enumerator.ToBlock = blockNumber
+	enumerator.Chunk = *chunk
+
+	owned, err := enumerator.TokenIDs(addresses)
+	if err != nil {
+		fatal(i18n.Errorf("err.enumerate_ids", err))
+	}
+	if *withMetadata {
+		resolver := erc721.NewMetadataResolver(*gateway, *cacheDir)
+		if err := attachMetadata(owned, mc, tokenAddr, *batch, resolver); err != nil {
+			logger.Warn("failed to fetch metadata", "err", err)
+		}
+	}
+
+	totalTokens, successCount := 0, 0
+	for i, o := range owned {
+		if !o.Success {
+			fmt.Println(i18n.T("progress.nfts.failed", i+1, o.Owner.Hex()))
+			continue
+		}
+		successCount++
+		totalTokens += len(o.TokenIDs)
+		fmt.Println(i18n.T("progress.nfts.owned", i+1, o.Owner.Hex(), len(o.TokenIDs), formatTokenIDs(o.TokenIDs)))
+		if int64(len(o.TokenIDs)) != o.Balance.Int64() {
+			fmt.Println(i18n.T("progress.nfts.mismatch", o.Balance, len(o.TokenIDs)))
+		}
+		for _, info := range o.Tokens {
+			if info.Metadata == nil {
+				fmt.Printf("   #%s ⚠️ %s\n", info.TokenID, info.Error)
+				continue
+			}
+			fmt.Println(i18n.T("progress.nfts.attributes", info.TokenID, info.Metadata.Name, info.Metadata.Image, len(info.Metadata.Attributes)))
+		}
+	}
+
+	fmt.Printf("\n--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.nfts.title", blockNumber))
+	fmt.Printf("--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.success_rate", successCount, len(addresses)))
+	fmt.Println(i18n.T("report.nfts.total", totalTokens))
+	fmt.Println(i18n.T("report.nfts.time", time.Since(startTime)))
+	fmt.Printf("--------------------------------------------------\n")
+
+	if *outPath != "" {
+		rep := nftReport{
+			ChainID:      client.ChainID.Int64(),
+			Block:        blockNumber,
+			TokenAddress: tokenAddr,
+			Owners:       owned,
+		}
+		if err := report.WriteJSON(*outPath, rep); err != nil {
+			logger.Warn("failed to write report", "path", *outPath, "err", err)
+		} else {
+			logger.Info("report written", "path", *outPath)
+		}
+	}
+}
+
+// attachMetadata 批量调用 tokenURI 并解析元数据，结果挂到每个钱包的 Tokens 上
+func attachMetadata(owned []erc721.OwnedTokens, caller core.BatchCaller, token common.Address, batch int, resolver *erc721.MetadataResolver) error {
+	var ids []*big.Int
+	for _, o := range owned {
+		ids = append(ids, o.TokenIDs...)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	logger.Info("fetching metadata", "nfts", len(ids))
+	uris, err := erc721.TokenURIs(caller, token, ids, batch)
+	if err != nil {
+		return err
+	}
+	infos := resolver.ResolveAll(ids, uris)
+	next := 0
+	for i := range owned {
+		n := len(owned[i].TokenIDs)
+		owned[i].Tokens = infos[next : next+n]
+		next += n
+	}
+	return nil
+}
+
+// formatTokenIDs 把 ID 列表格式化成 "#1, #2, ..."，太长时截断
+func formatTokenIDs(ids []*big.Int) string {
+	const maxShown = 10
+	var parts []string
+	for i, id := range ids {
+		if i == maxShown {
+			parts = append(parts, fmt.Sprintf("... +%d", len(ids)-maxShown))
+			break
+		}
+		parts = append(parts, "#"+id.String())
+	}
+	return strings.Join(parts, ", ")
+}
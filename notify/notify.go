@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts = 5                        // 每个 webhook 默认最多投递次数
+	DefaultBackoff     = 1 * time.Second          // 第一次重试前的等待时间，之后每次翻倍
+	MaxBackoff         = 30 * time.Second         // 重试等待时间上限
+	RequestTimeout     = 10 * time.Second         // 单次 POST 的超时时间
+	SignatureHeader    = "X-Chain-Lens-Signature" // sha256=<hex>，对 "<timestamp>.<body>" 做 HMAC-SHA256
+	TimestampHeader    = "X-Chain-Lens-Timestamp" // 签名时间 (unix 秒)，接收方可据此拒绝重放
+	EventHeader        = "X-Chain-Lens-Event"
+	DeliveryHeader     = "X-Chain-Lens-Delivery" // 投递 ID，重试时不变，接收方可据此去重
+)
+
+// 事件类型
+const (
+	EventRunSummary    = "run.summary"    // 一次 CLI 运行结束时的汇总
+	EventBalanceChange = "balance.change" // watch 模式下的余额变化 / 阈值事件
+)
+
+// Webhook 一个通知地址。Secret 为空时不签名；SecretEnv 非空时从环境变量读取密钥，避免写进配置文件，
+// 此时环境变量必须非空，否则 Validate 报错 (不会静默发送不签名的请求)。
+type Webhook struct {
+	URL       string `json:"url"`
+	Secret    string `json:"secret,omitempty"`
+	SecretEnv string `json:"secret_env,omitempty"`
+}
+
+// Config 通知配置，对应 config.json 里的 notify
+type Config struct {
+	Webhooks      []Webhook `json:"webhooks"`
+	MaxAttempts   int       `json:"max_attempts,omitempty"`    // 0 表示 DefaultMaxAttempts
+	DeadLetterDir string    `json:"dead_letter_dir,omitempty"` // 投递失败的事件写到这个目录，为空则丢弃
+}
+
+// Envelope 实际 POST 的请求体
+type Envelope struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data"`
+}
+
+// DeadLetter 投递失败后写到磁盘的记录
+type DeadLetter struct {
+	URL      string          `json:"url"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	Envelope json.RawMessage `json:"envelope"`
+}
+
+// Notifier 把事件 POST 到所有 webhook，失败时指数退避重试，最终失败的写入死信目录
+type Notifier struct {
+	Webhooks      []Webhook
+	MaxAttempts   int
+	Backoff       time.Duration
+	DeadLetterDir string
+	Client        *http.Client
+}
+
+func New(cfg Config) (*Notifier, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &Notifier{
+		Webhooks:      cfg.Webhooks,
+		MaxAttempts:   cfg.MaxAttempts,
+		Backoff:       DefaultBackoff,
+		DeadLetterDir: cfg.DeadLetterDir,
+		Client:        &http.Client{Timeout: RequestTimeout},
+	}, nil
+}
+
+// Validate 检查 webhook 的密钥：配置了 secret_env 但环境变量为空时报错
+func (c Config) Validate() error {
+	for _, hook := range c.Webhooks {
+		if hook.SecretEnv != "" && os.Getenv(hook.SecretEnv) == "" {
+			return fmt.Errorf("webhook %s: secret_env %s is not set or empty", hook.URL, hook.SecretEnv)
+		}
+	}
+	return nil
+}
+
+// Send 把一个事件投递到所有 webhook。每个 webhook 独立重试，返回所有最终失败的错误。
+func (n *Notifier) Send(event string, data any) error {
+	env := Envelope{ID: newID(), Event: event, Timestamp: time.Now().UTC(), Data: data}
+	body, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, hook := range n.Webhooks {
+		attempts, err := n.deliver(hook, env, body)
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("webhook %s: %w", hook.URL, err)
+		if dlErr := n.deadLetter(hook, attempts, err, env, body); dlErr != nil {
+			err = errors.Join(err, fmt.Errorf("dead letter: %w", dlErr))
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// deliver 投递一次事件，可重试的失败 (网络错误、429、5xx) 按退避重试，返回实际尝试次数
+func (n *Notifier) deliver(hook Webhook, env Envelope, body []byte) (int, error) {
+	maxAttempts := n.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	backoff := n.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = n.post(hook, env, body)
+		if err == nil || !retry || attempt >= maxAttempts {
+			return attempt, err
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, MaxBackoff)
+	}
+}
+
+// post 发送一次请求，返回错误是否值得重试
+func (n *Notifier) post(hook Webhook, env Envelope, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, env.Event)
+	req.Header.Set(DeliveryHeader, env.ID)
+	req.Header.Set(TimestampHeader, ts)
+	if secret := hook.secret(); secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(secret, ts, body))
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %s", resp.Status)
+	default:
+		return false, fmt.Errorf("status %s", resp.Status)
+	}
+}
+
+// deadLetter 把投递失败的事件写成单独的 JSON 文件
+func (n *Notifier) deadLetter(hook Webhook, attempts int, cause error, env Envelope, body []byte) error {
+	if n.DeadLetterDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(n.DeadLetterDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(DeadLetter{URL: hook.URL, Attempts: attempts, Error: cause.Error(), Envelope: body}, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.json", env.Timestamp.Format("20060102T150405"), env.ID)
+	return os.WriteFile(filepath.Join(n.DeadLetterDir, name), data, 0o644)
+}
+
+func (h Webhook) secret() string {
+	if h.SecretEnv != "" {
+		return os.Getenv(h.SecretEnv)
+	}
+	return h.Secret
+}
+
+// Sign 计算签名：HMAC-SHA256(secret, "<timestamp>.<body>") 的十六进制。接收方用同样的方式校验。
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
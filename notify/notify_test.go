@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNotifierRetryAndSignature(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		want := "sha256=" + Sign("s3cret", r.Header.Get(TimestampHeader), body)
+		if r.Header.Get(SignatureHeader) != want {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway) // 第一次失败，触发重试
+		}
+	}))
+	defer srv.Close()
+
+	n, err := New(Config{Webhooks: []Webhook{{URL: srv.URL, Secret: "s3cret"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Backoff = 0
+	if err := n.Send(EventRunSummary, map[string]int{"wallet_count": 3}); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 1 retry, got %d calls", calls.Load())
+	}
+}
+
+func TestNotifierDeadLetter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest) // 4xx 不重试
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	n, err := New(Config{Webhooks: []Webhook{{URL: srv.URL}}, DeadLetterDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Backoff = 0
+	if err := n.Send(EventBalanceChange, "payload"); err == nil {
+		t.Fatal("expected delivery error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("4xx must not be retried, got %d calls", calls.Load())
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected one dead letter, got %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	var dl DeadLetter
+	var env Envelope
+	if err := json.Unmarshal(data, &dl); err != nil || json.Unmarshal(dl.Envelope, &env) != nil {
+		t.Fatalf("invalid dead letter: %s", data)
+	}
+	if dl.URL != srv.URL || dl.Attempts != 1 || env.Event != EventBalanceChange || env.Data != "payload" {
+		t.Fatalf("unexpected dead letter: %+v %+v", dl, env)
+	}
+}
+
+func TestNewRequiresSecretEnv(t *testing.T) {
+	cfg := Config{Webhooks: []Webhook{{URL: "https://example.com/hook", SecretEnv: "CHAIN_LENS_TEST_SECRET"}}}
+	t.Setenv("CHAIN_LENS_TEST_SECRET", "")
+	if _, err := New(cfg); err == nil {
+		t.Fatal("empty secret_env must be an error, not an unsigned webhook")
+	}
+	t.Setenv("CHAIN_LENS_TEST_SECRET", "s3cret")
+	if _, err := New(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
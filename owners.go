@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"chain-lens/i18n"
+	"chain-lens/modules/erc721"
+	"chain-lens/modules/multicall"
+	"chain-lens/report"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ownerCount 某个持有人名下的 Token 数量
+type ownerCount struct {
+	Owner common.Address `json:"owner"`
+	Count int            `json:"count"`
+}
+
+// ownersReport owners 子命令输出的 JSON 结构
+type ownersReport struct {
+	ChainID      int64              `json:"chain_id"`
+	Block        uint64             `json:"block"`
+	TokenAddress common.Address     `json:"token_address"`
+	Tokens       []erc721.Ownership `json:"tokens"`
+	Missing      []*big.Int         `json:"missing"` // ownerOf revert：已销毁或不存在
+	Owners       []ownerCount       `json:"owners"`
+	NotExpected  []*big.Int         `json:"not_expected,omitempty"` // 指定 -expect 时，不在该地址名下的 ID
+}
+
+// runOwners owners 子命令：给定一批 Token ID，批量查询 ownerOf
+//
+//	chain-lens owners -ids ids.txt [-expect 0xVault]
+//	chain-lens owners -range 1-10000
+func runOwners(args []string) {
+	fs := flag.NewFlagSet("owners", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	idsPath := fs.String("ids", "", "包含 Token ID 的文件路径 (每行一个，十进制或 0x 十六进制)")
+	idRange := fs.String("range", "", "Token ID 区间，例如 1-10000 (含两端)")
+	expect := fs.String("expect", "", "期望的持有人地址，列出不在该地址名下的 ID")
+	batch := fs.Int("batch", erc721.DefaultBatchSize, "每次 Multicall 打包的调用数")
+	outPath := fs.String("out", "", "把结果写成 JSON 文件")
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	var ids []*big.Int
+	var err error
+	switch {
+	case *idsPath != "":
+		ids, err = loadTokenIDs(*idsPath)
+	case *idRange != "":
+		ids, err = parseIDRange(*idRange)
+	default:
+		fatal(i18n.Errorf("err.ids_required"))
+	}
+	if err != nil {
+		fatal(i18n.Errorf("err.load_token_ids", err))
+	}
+	var expected *common.Address
+	if *expect != "" {
+		if !common.IsHexAddress(*expect) {
+			fatal(i18n.Errorf("err.invalid_address", *expect))
+		}
+		addr := common.HexToAddress(*expect)
+		expected = &addr
+	}
+	logger.Info("token ids loaded", "count", len(ids))
+
+	cfg := loadConfig(*configPath)
+	tokenType, err := ParseTokenType(cfg.TokenType)
+	if err != nil {
+		fatal(err)
+	}
+	if tokenType != multicall.TokenTypeERC721 {
+		fatal(i18n.Errorf("err.owners_erc721_only"))
+	}
+	client, err := connect(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	defer client.Close()
+
+	startTime := time.Now()
+	blockNumber, err := client.Client.BlockNumber(context.Background())
+	if err != nil {
+		fatal(i18n.Errorf("err.block_number", err))
+	}
+	mc, err := newMultiChecker(cfg, client, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		fatal(err)
+	}
+
+	tokenAddr := common.HexToAddress(cfg.TokenAddress)
+	ownerships, err := erc721.OwnersOf(mc, tokenAddr, ids, *batch)
+	if err != nil {
+		fatal(i18n.Errorf("err.owners_of", err))
+	}
+
+	rep := ownersReport{
+		ChainID:      client.ChainID.Int64(),
+		Block:        blockNumber,
+		TokenAddress: tokenAddr,
+		Tokens:       ownerships,
+		Missing:      []*big.Int{},
+	}
+	counts := make(map[common.Address]int)
+	for _, o := range ownerships {
+		if !o.Exists {
+			rep.Missing = append(rep.Missing, o.TokenID)
+			fmt.Println(i18n.T("progress.owners.missing", o.TokenID))
+			continue
+		}
+		counts[o.Owner]++
+		if expected != nil && o.Owner != *expected {
+			rep.NotExpected = append(rep.NotExpected, o.TokenID)
+		}
+		fmt.Printf("✅ #%s | %s\n", o.TokenID, o.Owner.Hex())
+	}
+	for owner, n := range counts {
+		rep.Owners = append(rep.Owners, ownerCount{Owner: owner, Count: n})
+	}
+	sort.Slice(rep.Owners, func(i, j int) bool {
+		if rep.Owners[i].Count != rep.Owners[j].Count {
+			return rep.Owners[i].Count > rep.Owners[j].Count
+		}
+		return rep.Owners[i].Owner.Cmp(rep.Owners[j].Owner) < 0
+	})
+
+	fmt.Printf("\n--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.owners.title", blockNumber))
+	fmt.Printf("--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.owners.ids", len(ids)))
+	fmt.Println(i18n.T("report.owners.distinct", len(rep.Owners)))
+	fmt.Println(i18n.T("report.owners.missing", len(rep.Missing)))
+	for i, oc := range rep.Owners {
+		if i == 20 {
+			fmt.Println(i18n.T("report.owners.more", len(rep.Owners)-20))
+			break
+		}
+		fmt.Printf("   %s | %d\n", oc.Owner.Hex(), oc.Count)
+	}
+	if expected != nil {
+		if len(rep.NotExpected) == 0 {
+			fmt.Println(i18n.T("report.owners.all_expected", expected.Hex()))
+		} else {
+			fmt.Println(i18n.T("report.owners.not_expected", len(rep.NotExpected), expected.Hex(), formatTokenIDs(rep.NotExpected)))
+		}
+	}
+	fmt.Println(i18n.T("report.owners.time", time.Since(startTime)))
+	fmt.Printf("--------------------------------------------------\n")
+
+	if *outPath != "" {
+		if err := report.WriteJSON(*outPath, rep); err != nil {
+			logger.Warn("failed to write report", "path", *outPath, "err", err)
+		} else {
+			logger.Info("report written", "path", *outPath)
+		}
+	}
+}
+
+// loadTokenIDs 从文件读取 Token ID，跳过空行和注释
+func loadTokenIDs(path string) ([]*big.Int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var ids []*big.Int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		id, ok := parseTokenID(line)
+		if !ok {
+			logger.Warn("skipping invalid token id", "line", line)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, scanner.Err()
+}
+
+// parseTokenID 解析单个 Token ID：0x 开头按十六进制，否则按十进制 (前导 0 不当作八进制，与 -range 一致)
+func parseTokenID(s string) (*big.Int, bool) {
+	base := 10
+	if hex, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		s, base = hex, 16
+	}
+	if s == "" || strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		return nil, false
+	}
+	return new(big.Int).SetString(s, base)
+}
+
+// parseIDRange 解析 "start-end" 形式的区间 (含两端)
+func parseIDRange(s string) ([]*big.Int, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid range %q, expected start-end", s)
+	}
+	start, ok1 := new(big.Int).SetString(strings.TrimSpace(startStr), 10)
+	end, ok2 := new(big.Int).SetString(strings.TrimSpace(endStr), 10)
+	if !ok1 || !ok2 || start.Sign() < 0 || start.Cmp(end) > 0 {
+		return nil, fmt.Errorf("invalid range %q", s)
+	}
+	const maxRange = 1_000_000
+	if new(big.Int).Sub(end, start).Cmp(big.NewInt(maxRange)) >= 0 {
+		return nil, fmt.Errorf("range %q too large (max %d ids)", s, maxRange)
+	}
+	var ids []*big.Int
+	for id := new(big.Int).Set(start); id.Cmp(end) <= 0; id.Add(id, big.NewInt(1)) {
+		ids = append(ids, new(big.Int).Set(id))
+	}
+	return ids, nil
+}
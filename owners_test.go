@@ -0,0 +1,75 @@
+package main
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func idStrings(ids []*big.Int) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
+
+func TestLoadTokenIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids.txt")
+	content := "# 注释\n1\n010\n0x1f\n0XFF\n\n// 另一种注释\n0b11\n0o7\n1_000\n-3\n0x\nabc\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := loadTokenIDs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 010 按十进制解析；0b / 0o / 下划线 / 负数等格式被跳过
+	want := []string{"1", "10", "31", "255"}
+	got := idStrings(ids)
+	if len(got) != len(want) {
+		t.Fatalf("ids = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ids = %v, want %v", got, want)
+		}
+	}
+
+	if _, err := loadTokenIDs(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Fatal("missing file must be an error")
+	}
+}
+
+func TestParseIDRange(t *testing.T) {
+	ids, err := parseIDRange(" 8 - 12 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := idStrings(ids); len(got) != 5 || got[0] != "8" || got[4] != "12" {
+		t.Fatalf("ids = %v, want 8..12", got)
+	}
+	if ids, err := parseIDRange("5-5"); err != nil || len(ids) != 1 {
+		t.Fatalf("single id range: %v, %v", ids, err)
+	}
+	// 最大 1_000_000 个 ID
+	if ids, err := parseIDRange("1-1000000"); err != nil || len(ids) != 1_000_000 {
+		t.Fatalf("max range: %d ids, %v", len(ids), err)
+	}
+
+	invalid := []string{
+		"10",          // 缺少 -
+		"12-8",        // 反向
+		"a-5",         // 非数字
+		"0x1-0x5",     // 只接受十进制
+		"-1-5",        // 负数
+		"1-1000001",   // 超过上限
+		"0-999999999", // 超过上限
+	}
+	for _, s := range invalid {
+		if _, err := parseIDRange(s); err == nil {
+			t.Errorf("parseIDRange(%q) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package progress
+
+import (
+	"sync"
+	"time"
+)
+
+// State 某一时刻的扫描进度。钱包数按 钱包×资产 计算。
+type State struct {
+	Batches      int           `json:"batches"`       // 已完成的批次
+	TotalBatches int           `json:"total_batches"` // 总批次
+	Wallets      int           `json:"wallets"`       // 已完成的钱包 (含补查)
+	TotalWallets int           `json:"total_wallets"` // 总钱包数
+	Failed       int           `json:"failed"`        // 补查后仍然失败的钱包
+	Calls        int           `json:"calls"`         // 批量调用里执行的子调用
+	FailedCalls  int           `json:"failed_calls"`  // 批量调用里失败、需要补查的子调用
+	Retries      int           `json:"retries"`       // 已完成的逐个补查
+	Started      time.Time     `json:"started"`
+	Elapsed      time.Duration `json:"elapsed_ns"`
+}
+
+// Fraction 完成比例 (0 ~ 1)
+func (s State) Fraction() float64 {
+	if s.TotalWallets == 0 {
+		return 1
+	}
+	return float64(s.Wallets) / float64(s.TotalWallets)
+}
+
+// ETA 按目前的速度估算的剩余时间，还没有完成任何钱包时返回 0 (未知)
+func (s State) ETA() time.Duration {
+	if s.Wallets == 0 || s.Wallets >= s.TotalWallets {
+		return 0
+	}
+	return time.Duration(float64(s.Elapsed) * float64(s.TotalWallets-s.Wallets) / float64(s.Wallets))
+}
+
+// Done 所有批次都已完成
+func (s State) Done() bool {
+	return s.Batches >= s.TotalBatches
+}
+
+// Tracker 并发安全的进度计数，由批量查询和补查的 worker 调用；每次变化后把最新状态交给 OnUpdate。
+// OnUpdate 串行调用，应当尽快返回。nil 的 *Tracker 上的方法什么也不做，调用方不需要判空。
+type Tracker struct {
+	OnUpdate func(State)
+	mu       sync.Mutex
+	state    State
+}
+
+func NewTracker(totalBatches, totalWallets int, onUpdate func(State)) *Tracker {
+	return &Tracker{
+		OnUpdate: onUpdate,
+		state:    State{TotalBatches: totalBatches, TotalWallets: totalWallets, Started: time.Now()},
+	}
+}
+
+// Calls 记录一次批量调用：子调用数和其中失败的数量
+func (t *Tracker) Calls(calls, failed int) {
+	t.update(func(s *State) {
+		s.Calls += calls
+		s.FailedCalls += failed
+	})
+}
+
+// Retried 记录一次逐个补查
+func (t *Tracker) Retried() {
+	t.update(func(s *State) { s.Retries++ })
+}
+
+// BatchDone 记录完成的一批：钱包数和补查后仍然失败的数量
+func (t *Tracker) BatchDone(wallets, failed int) {
+	t.update(func(s *State) {
+		s.Batches++
+		s.Wallets += wallets
+		s.Failed += failed
+	})
+}
+
+// State 当前进度
+func (t *Tracker) State() State {
+	if t == nil {
+		return State{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.state
+	s.Elapsed = time.Since(s.Started)
+	return s
+}
+
+func (t *Tracker) update(fn func(*State)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.state)
+	if t.OnUpdate != nil {
+		s := t.state
+		s.Elapsed = time.Since(s.Started)
+		t.OnUpdate(s)
+	}
+}
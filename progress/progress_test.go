@@ -0,0 +1,65 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	var last State
+	updates := 0
+	tr := NewTracker(2, 10, func(s State) { last = s; updates++ })
+	tr.Calls(5, 2)
+	tr.Retried()
+	tr.Retried()
+	tr.BatchDone(5, 1)
+	if last.Batches != 1 || last.Wallets != 5 || last.Failed != 1 || last.Calls != 5 || last.FailedCalls != 2 || last.Retries != 2 {
+		t.Fatalf("unexpected state: %+v", last)
+	}
+	if updates != 4 {
+		t.Errorf("updates = %d, want 4", updates)
+	}
+	if last.Done() || last.Fraction() != 0.5 {
+		t.Errorf("half way: done=%v fraction=%v", last.Done(), last.Fraction())
+	}
+	tr.BatchDone(5, 0)
+	if s := tr.State(); !s.Done() || s.ETA() != 0 {
+		t.Errorf("finished state: %+v", s)
+	}
+
+	// nil Tracker 上的调用什么也不做
+	var nilTracker *Tracker
+	nilTracker.Calls(1, 0)
+	nilTracker.BatchDone(1, 0)
+	if s := nilTracker.State(); s.Batches != 0 {
+		t.Errorf("nil tracker state: %+v", s)
+	}
+}
+
+func TestETA(t *testing.T) {
+	s := State{Wallets: 25, TotalWallets: 100, Elapsed: 10 * time.Second}
+	if eta := s.ETA(); eta != 30*time.Second {
+		t.Errorf("ETA = %v, want 30s", eta)
+	}
+	if eta := (State{TotalWallets: 100, Elapsed: time.Second}).ETA(); eta != 0 {
+		t.Errorf("ETA before any progress = %v, want 0", eta)
+	}
+}
+
+func TestReporterBar(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Reporter{Mode: ModeBar, Out: &buf}
+	r.Update(State{Batches: 1, TotalBatches: 2, Wallets: 50, TotalWallets: 100, Elapsed: time.Second})
+	r.Update(State{Batches: 2, TotalBatches: 2, Wallets: 100, TotalWallets: 100, Elapsed: 2 * time.Second})
+	r.Finish()
+	out := buf.String()
+	if !strings.HasPrefix(out, "\r") || !strings.HasSuffix(out, "\n") || !strings.Contains(out, "100/100") {
+		t.Errorf("unexpected bar output: %q", out)
+	}
+
+	if _, err := ParseMode("sometimes"); err == nil {
+		t.Error("unknown mode must be rejected")
+	}
+}
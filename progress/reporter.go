@@ -0,0 +1,147 @@
+package progress
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultLogInterval = 10 * time.Second       // Log 模式两行进度之间的最短间隔
+	barRefresh         = 100 * time.Millisecond // 进度条刷新间隔，避免刷屏拖慢终端
+	barWidth           = 30
+)
+
+// Mode 进度的输出方式
+type Mode string
+
+const (
+	ModeAuto Mode = "auto" // 输出是终端时用进度条，否则定期打日志
+	ModeBar  Mode = "bar"  // 原地刷新的进度条
+	ModeLog  Mode = "log"  // 定期输出一行 info 日志
+	ModeOff  Mode = "off"  // 不输出
+)
+
+// ParseMode 解析 -progress 参数，空字符串视为 auto
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case "":
+		return ModeAuto, nil
+	case ModeAuto, ModeBar, ModeLog, ModeOff:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown progress mode: %s (valid: auto, bar, log, off)", s)
+	}
+}
+
+// Reporter 把 Tracker 的状态输出给人看，Update 可以直接作为 Tracker 的 OnUpdate
+type Reporter struct {
+	Mode     Mode        // bar / log / off (auto 在 NewReporter 里已经解析)
+	Out      io.Writer   // 进度条的输出，通常是 os.Stderr
+	Logger   core.Logger // Log 模式的日志器，nil 表示 core.DefaultLogger
+	Interval time.Duration
+
+	mu    sync.Mutex
+	last  time.Time
+	state State
+	seen  bool
+}
+
+// NewReporter 创建输出到 out 的 Reporter，auto 模式下 out 是终端时用进度条，否则打日志
+func NewReporter(mode Mode, out *os.File, logger core.Logger) *Reporter {
+	if mode == ModeAuto || mode == "" {
+		mode = ModeLog
+		if IsTerminal(out) {
+			mode = ModeBar
+		}
+	}
+	return &Reporter{Mode: mode, Out: out, Logger: logger, Interval: DefaultLogInterval}
+}
+
+// IsTerminal f 是否是字符设备 (终端)
+func IsTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Update 记录最新状态，按模式节流输出
+func (r *Reporter) Update(s State) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state, r.seen = s, true
+	switch r.Mode {
+	case ModeBar:
+		if time.Since(r.last) >= barRefresh || s.Done() {
+			r.drawBar()
+		}
+	case ModeLog:
+		// 最后一行交给 Finish，避免完成时重复输出
+		if !s.Done() && time.Since(r.last) >= r.interval() && time.Since(s.Started) >= r.interval() {
+			r.logLine("scan progress")
+		}
+	}
+}
+
+// Finish 输出最终状态；进度条模式下换行，之后的输出不会覆盖进度条
+func (r *Reporter) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.seen {
+		return
+	}
+	switch r.Mode {
+	case ModeBar:
+		r.drawBar()
+		fmt.Fprintln(r.Out)
+	case ModeLog:
+		r.logLine("scan finished")
+	}
+	r.seen = false
+}
+
+func (r *Reporter) interval() time.Duration {
+	if r.Interval <= 0 {
+		return DefaultLogInterval
+	}
+	return r.Interval
+}
+
+func (r *Reporter) drawBar() {
+	s := r.state
+	filled := int(s.Fraction() * barWidth)
+	bar := "[" + strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled) + "]"
+	line := i18n.T("progress.bar", bar, s.Fraction()*100, s.Batches, s.TotalBatches, s.Wallets, s.TotalWallets, s.Failed, s.Retries, formatETA(s))
+	// \r 回到行首，\x1b[K 清掉上一次更长的残留
+	fmt.Fprintf(r.Out, "\r%s\x1b[K", line)
+	r.last = time.Now()
+}
+
+func (r *Reporter) logLine(msg string) {
+	s := r.state
+	core.LoggerOrDefault(r.Logger).Info(msg,
+		"batches", s.Batches, "total_batches", s.TotalBatches,
+		"wallets", s.Wallets, "total_wallets", s.TotalWallets,
+		"failed", s.Failed, "retries", s.Retries,
+		"percent", fmt.Sprintf("%.1f", s.Fraction()*100),
+		"elapsed", s.Elapsed.Round(time.Second), "eta", formatETA(s))
+	r.last = time.Now()
+}
+
+func formatETA(s State) string {
+	if s.Done() {
+		return "0s"
+	}
+	eta := s.ETA()
+	if eta == 0 {
+		return "--"
+	}
+	return eta.Round(time.Second).String()
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Change 单个钱包在两个快照之间的余额变化
+type Change struct {
+	Owner common.Address
+	From  *big.Float
+	To    *big.Float
+	Delta *big.Float
+}
+
+// Diff 两个快照的对比结果
+type Diff struct {
+	From, To      *Snapshot
+	Changes       []Change         // 余额发生变化的钱包，按变化量绝对值从大到小排序
+	NewHolders    []common.Address // 之前余额为 0 (或不在列表里)，现在大于 0
+	ExitedHolders []common.Address // 之前余额大于 0，现在为 0 (或不在列表里)
+	Skipped       []common.Address // 任意一边查询失败，无法比较
+	TotalFrom     *big.Float
+	TotalTo       *big.Float
+	TotalDelta    *big.Float
+}
+
+// Compare 对比两个快照。只在一边出现的钱包按另一边余额为 0 处理。
+func Compare(from, to *Snapshot) *Diff {
+	d := &Diff{
+		From:       from,
+		To:         to,
+		TotalFrom:  newFloat(),
+		TotalTo:    newFloat(),
+		TotalDelta: newFloat(),
+	}
+
+	fromEntries := indexEntries(from)
+	toEntries := indexEntries(to)
+
+	// 保持稳定的遍历顺序：先 from 的顺序，再补上只在 to 里出现的钱包
+	var owners []common.Address
+	seen := make(map[common.Address]bool)
+	for _, snap := range []*Snapshot{from, to} {
+		for _, e := range snap.Balances {
+			if !seen[e.Owner] {
+				seen[e.Owner] = true
+				owners = append(owners, e.Owner)
+			}
+		}
+	}
+
+	for _, owner := range owners {
+		fe, inFrom := fromEntries[owner]
+		te, inTo := toEntries[owner]
+		if (inFrom && !fe.Success) || (inTo && !te.Success) {
+			d.Skipped = append(d.Skipped, owner)
+			continue
+		}
+
+		fromBal, toBal := newFloat(), newFloat()
+		if inFrom {
+			fromBal = ParseBalance(fe.Balance)
+		}
+		if inTo {
+			toBal = ParseBalance(te.Balance)
+		}
+		d.TotalFrom.Add(d.TotalFrom, fromBal)
+		d.TotalTo.Add(d.TotalTo, toBal)
+
+		if fromBal.Cmp(toBal) == 0 {
+			continue
+		}
+		delta := newFloat().Sub(toBal, fromBal)
+		d.Changes = append(d.Changes, Change{Owner: owner, From: fromBal, To: toBal, Delta: delta})
+
+		switch {
+		case fromBal.Sign() == 0 && toBal.Sign() > 0:
+			d.NewHolders = append(d.NewHolders, owner)
+		case fromBal.Sign() > 0 && toBal.Sign() == 0:
+			d.ExitedHolders = append(d.ExitedHolders, owner)
+		}
+	}
+	d.TotalDelta.Sub(d.TotalTo, d.TotalFrom)
+
+	sort.SliceStable(d.Changes, func(i, j int) bool {
+		ai := newFloat().Abs(d.Changes[i].Delta)
+		aj := newFloat().Abs(d.Changes[j].Delta)
+		return ai.Cmp(aj) > 0
+	})
+	return d
+}
+
+func indexEntries(snap *Snapshot) map[common.Address]Entry {
+	m := make(map[common.Address]Entry, len(snap.Balances))
+	for _, e := range snap.Balances {
+		m[e.Owner] = e
+	}
+	return m
+}
+
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(balancePrec)
+}
@@ -0,0 +1,51 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCompare(t *testing.T) {
+	a := common.HexToAddress("0x01")
+	b := common.HexToAddress("0x02")
+	c := common.HexToAddress("0x03")
+	d := common.HexToAddress("0x04")
+	e := common.HexToAddress("0x05")
+
+	from := &Snapshot{Block: 100, Balances: []Entry{
+		{Owner: a, Balance: "10", Success: true},
+		{Owner: b, Balance: "5", Success: true},
+		{Owner: c, Balance: "0", Success: true},
+		{Owner: e, Balance: "1", Success: false},
+	}}
+	to := &Snapshot{Block: 200, Balances: []Entry{
+		{Owner: a, Balance: "12.5", Success: true},
+		{Owner: b, Balance: "0", Success: true},
+		{Owner: c, Balance: "3", Success: true},
+		{Owner: d, Balance: "1", Success: true},
+		{Owner: e, Balance: "2", Success: true},
+	}}
+
+	diff := Compare(from, to)
+
+	if len(diff.Changes) != 4 {
+		t.Fatalf("expected 4 changes, got %d", len(diff.Changes))
+	}
+	// 按变化量绝对值排序：b(-5) c(+3) a(+2.5) d(+1)
+	if diff.Changes[0].Owner != b || diff.Changes[3].Owner != d {
+		t.Fatalf("unexpected order: %+v", diff.Changes)
+	}
+	if len(diff.NewHolders) != 2 || diff.NewHolders[0] != c || diff.NewHolders[1] != d {
+		t.Fatalf("unexpected new holders: %v", diff.NewHolders)
+	}
+	if len(diff.ExitedHolders) != 1 || diff.ExitedHolders[0] != b {
+		t.Fatalf("unexpected exited holders: %v", diff.ExitedHolders)
+	}
+	if len(diff.Skipped) != 1 || diff.Skipped[0] != e {
+		t.Fatalf("unexpected skipped: %v", diff.Skipped)
+	}
+	if got := diff.TotalDelta.Text('f', -1); got != "1.5" {
+		t.Fatalf("expected total delta 1.5, got %s", got)
+	}
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ChainReport 单条链上各个资产的快照，Error 不为空表示这条链整体失败
+type ChainReport struct {
+	Network string      `json:"network"`
+	ChainID int64       `json:"chain_id"`
+	Block   uint64      `json:"block"`
+	Assets  []*Snapshot `json:"assets"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// MultiChainReport 同一组钱包在多条链上的合并报告
+type MultiChainReport struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Chains    []ChainReport `json:"chains"`
+	Totals    []AssetTotal  `json:"totals"`  // 按资产 (AssetKey) 跨链汇总
+	Wallets   []WalletTotal `json:"wallets"` // 每个钱包按资产跨链汇总
+}
+
+// AssetTotal 某个资产 (按 AssetKey 归并) 的跨链合计和各链合计
+type AssetTotal struct {
+	Asset    string                `json:"asset"`
+	Symbol   string                `json:"symbol"`
+	Total    *big.Float            `json:"total"`
+	PerChain map[string]*big.Float `json:"per_chain"`
+}
+
+// WalletTotal 单个钱包每个资产的跨链合计，key 为 AssetKey
+type WalletTotal struct {
+	Owner    common.Address        `json:"owner"`
+	Balances map[string]*big.Float `json:"balances"`
+}
+
+// AssetKey 跨链汇总时资产的身份：配置了归并名时用归并名 (如各链上的 USDC 都配成 "USDC")，
+// 否则用 symbol + 合约地址，避免同名的不同代币 (两个 ERC721、USDC 和桥接的 USDC) 被加在一起。
+// 原生币没有合约地址，只按 symbol 归并 (各链上的 ETH)。
+func AssetKey(snap *Snapshot) string {
+	switch {
+	case snap.Asset != "":
+		return snap.Asset
+	case snap.TokenAddress == (common.Address{}):
+		return snap.Symbol
+	case snap.Symbol == "":
+		return snap.TokenAddress.Hex()
+	default:
+		return snap.Symbol + "@" + snap.TokenAddress.Hex()
+	}
+}
+
+// NewMultiChainReport 汇总各链结果，同一 AssetKey 视为同一资产，失败的钱包不计入合计。
+func NewMultiChainReport(chains []ChainReport) *MultiChainReport {
+	rep := &MultiChainReport{Timestamp: time.Now().UTC(), Chains: chains}
+	totals := map[string]*AssetTotal{}
+	wallets := map[common.Address]*WalletTotal{}
+	var order []common.Address
+
+	for _, chain := range chains {
+		for _, snap := range chain.Assets {
+			key := AssetKey(snap)
+			total, ok := totals[key]
+			if !ok {
+				total = &AssetTotal{Asset: key, Symbol: snap.Symbol, Total: newFloat(), PerChain: map[string]*big.Float{}}
+				totals[key] = total
+			}
+			perChain, ok := total.PerChain[chain.Network]
+			if !ok {
+				perChain = newFloat()
+				total.PerChain[chain.Network] = perChain
+			}
+			for _, e := range snap.Balances {
+				w, ok := wallets[e.Owner]
+				if !ok {
+					w = &WalletTotal{Owner: e.Owner, Balances: map[string]*big.Float{}}
+					wallets[e.Owner] = w
+					order = append(order, e.Owner)
+				}
+				if !e.Success {
+					continue
+				}
+				balance := ParseBalance(e.Balance)
+				total.Total.Add(total.Total, balance)
+				perChain.Add(perChain, balance)
+				if w.Balances[key] == nil {
+					w.Balances[key] = newFloat()
+				}
+				w.Balances[key].Add(w.Balances[key], balance)
+			}
+		}
+	}
+
+	for _, t := range totals {
+		rep.Totals = append(rep.Totals, *t)
+	}
+	sort.Slice(rep.Totals, func(i, j int) bool { return rep.Totals[i].Asset < rep.Totals[j].Asset })
+	for _, owner := range order {
+		rep.Wallets = append(rep.Wallets, *wallets[owner])
+	}
+	return rep
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNewMultiChainReport(t *testing.T) {
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	snap := func(symbol string, entries ...Entry) *Snapshot {
+		return &Snapshot{Symbol: symbol, Balances: entries}
+	}
+	rep := NewMultiChainReport([]ChainReport{
+		{Network: "ethereum", Assets: []*Snapshot{
+			snap("ETH", Entry{Owner: alice, Balance: "1.5", Success: true}, Entry{Owner: bob, Balance: "2", Success: true}),
+			snap("USDC", Entry{Owner: alice, Balance: "100", Success: true}, Entry{Owner: bob, Balance: "999", Success: false}),
+		}},
+		{Network: "arbitrum", Assets: []*Snapshot{
+			snap("ETH", Entry{Owner: alice, Balance: "0.5", Success: true}, Entry{Owner: bob, Balance: "0", Success: true}),
+		}},
+		{Network: "base", Error: "rpc down"},
+	})
+
+	if len(rep.Totals) != 2 || rep.Totals[0].Symbol != "ETH" || rep.Totals[1].Symbol != "USDC" {
+		t.Fatalf("unexpected totals: %+v", rep.Totals)
+	}
+	eth := rep.Totals[0]
+	if eth.Total.Text('f', -1) != "4" || eth.PerChain["arbitrum"].Text('f', -1) != "0.5" {
+		t.Fatalf("unexpected ETH total: %s %v", eth.Total.Text('f', -1), eth.PerChain)
+	}
+	if rep.Totals[1].Total.Text('f', -1) != "100" {
+		t.Fatalf("failed balances must not be counted: %s", rep.Totals[1].Total.Text('f', -1))
+	}
+	if len(rep.Wallets) != 2 || rep.Wallets[0].Owner != alice || rep.Wallets[0].Balances["ETH"].Text('f', -1) != "2" {
+		t.Fatalf("unexpected wallet totals: %+v", rep.Wallets)
+	}
+	if _, ok := rep.Wallets[1].Balances["USDC"]; ok {
+		t.Fatal("bob has no successful USDC balance")
+	}
+}
+
+func TestNewMultiChainReportSameSymbol(t *testing.T) {
+	alice := common.HexToAddress("0xa1")
+	usdc := common.HexToAddress("0xaa")
+	bridged := common.HexToAddress("0xbb")
+	snap := func(token common.Address, asset, balance string) *Snapshot {
+		return &Snapshot{TokenAddress: token, Symbol: "USDC", Asset: asset, Balances: []Entry{{Owner: alice, Balance: balance, Success: true}}}
+	}
+	// 同一条链上 symbol 相同的两个代币分开汇总；配置了归并名的才跨链合并
+	rep := NewMultiChainReport([]ChainReport{
+		{Network: "ethereum", Assets: []*Snapshot{snap(usdc, "", "1"), snap(bridged, "", "2")}},
+		{Network: "base", Assets: []*Snapshot{snap(usdc, "usdc", "10")}},
+		{Network: "arbitrum", Assets: []*Snapshot{snap(bridged, "usdc", "20")}},
+	})
+
+	want := map[string]string{"USDC@" + usdc.Hex(): "1", "USDC@" + bridged.Hex(): "2", "usdc": "30"}
+	if len(rep.Totals) != len(want) {
+		t.Fatalf("unexpected totals: %+v", rep.Totals)
+	}
+	for _, total := range rep.Totals {
+		if total.Total.Text('f', -1) != want[total.Asset] {
+			t.Errorf("%s total = %s, want %s", total.Asset, total.Total.Text('f', -1), want[total.Asset])
+		}
+		if b := rep.Wallets[0].Balances[total.Asset]; b == nil || b.Text('f', -1) != want[total.Asset] {
+			t.Errorf("alice %s = %v, want %s", total.Asset, b, want[total.Asset])
+		}
+	}
+}
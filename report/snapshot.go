@@ -0,0 +1,131 @@
+package report
+
+import (
+	"chain-lens/core"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// balancePrec 解析余额字符串时使用的精度，足够覆盖 uint256 + 18 位小数
+const balancePrec = 512
+
+// Snapshot 某个区块上一组钱包余额的快照，也是 -out 输出的 JSON 结构
+type Snapshot struct {
+	ChainID      int64          `json:"chain_id"`
+	Block        uint64         `json:"block"`
+	TokenAddress common.Address `json:"token_address"`
+	Symbol       string         `json:"symbol"`
+	Asset        string         `json:"asset,omitempty"` // 多链模式下配置的归并名，见 AssetKey
+	Timestamp    time.Time      `json:"timestamp"`
+	Balances     []Entry        `json:"balances"`
+	Stats        *Stats         `json:"stats,omitempty"` // 开启统计模式时附带的分布统计
+	PriceUSD     *big.Float     `json:"price_usd,omitempty"`
+	TotalUSD     *big.Float     `json:"total_usd,omitempty"`
+}
+
+// Entry 快照里单个钱包的余额
+type Entry struct {
+	Owner    common.Address       `json:"owner"`
+	Balance  string               `json:"balance"` // 十进制字符串，避免 JSON 浮点精度丢失
+	Success  bool                 `json:"success"`
+	ValueUSD *big.Float           `json:"value_usd,omitempty"` // 配置了报价路由时的美元价值
+	Profile  *core.AccountProfile `json:"profile,omitempty"`   // 开启 inspect 时的地址画像
+}
+
+// NewSnapshot 把查询结果转换成快照
+func NewSnapshot(chainID int64, block uint64, tokenAddr common.Address, balances []core.TokenBalance) *Snapshot {
+	snap := &Snapshot{
+		ChainID:      chainID,
+		Block:        block,
+		TokenAddress: tokenAddr,
+		Timestamp:    time.Now().UTC(),
+		Balances:     make([]Entry, 0, len(balances)),
+	}
+	for _, tb := range balances {
+		if snap.Symbol == "" && tb.Symbol != "" {
+			snap.Symbol = tb.Symbol
+		}
+		e := Entry{Owner: tb.Owner, Success: tb.Success}
+		if tb.Balance != nil {
+			e.Balance = tb.Balance.Text('f', -1)
+		}
+		snap.Balances = append(snap.Balances, e)
+	}
+	return snap
+}
+
+// ApplyPrice 按美元单价计算每个钱包和合计的美元价值
+func (s *Snapshot) ApplyPrice(usd *big.Float) {
+	s.PriceUSD = usd
+	s.TotalUSD = newFloat()
+	for i, e := range s.Balances {
+		if !e.Success {
+			continue
+		}
+		value := newFloat().Mul(ParseBalance(e.Balance), usd)
+		s.Balances[i].ValueUSD = value
+		s.TotalUSD.Add(s.TotalUSD, value)
+	}
+}
+
+// AttachProfiles 按地址把画像挂到对应的余额条目上
+func (s *Snapshot) AttachProfiles(profiles []core.AccountProfile) {
+	byOwner := make(map[common.Address]*core.AccountProfile, len(profiles))
+	for i := range profiles {
+		byOwner[profiles[i].Address] = &profiles[i]
+	}
+	for i, e := range s.Balances {
+		s.Balances[i].Profile = byOwner[e.Owner]
+	}
+}
+
+// TokenBalances 把快照还原成查询结果
+func (s *Snapshot) TokenBalances() []core.TokenBalance {
+	balances := make([]core.TokenBalance, 0, len(s.Balances))
+	for _, e := range s.Balances {
+		balances = append(balances, core.TokenBalance{
+			Symbol:       s.Symbol,
+			TokenAddress: s.TokenAddress,
+			Balance:      ParseBalance(e.Balance),
+			Owner:        e.Owner,
+			Success:      e.Success,
+		})
+	}
+	return balances
+}
+
+// ParseBalance 解析十进制余额字符串，非法或为空时返回 0
+func ParseBalance(s string) *big.Float {
+	f, ok := new(big.Float).SetPrec(balancePrec).SetString(s)
+	if !ok {
+		return new(big.Float).SetPrec(balancePrec)
+	}
+	return f
+}
+
+// WriteJSON 把快照 (或其他报告结构) 写入 JSON 文件
+func WriteJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// ReadJSON 从 JSON 文件读取快照
+func ReadJSON(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
@@ -0,0 +1,173 @@
+package report
+
+import (
+	"chain-lens/core"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultTopN 默认展示的头部持有人数量
+const DefaultTopN = 10
+
+// Stats 持有人分布统计，只统计查询成功且余额大于 0 的地址
+type Stats struct {
+	Wallets      int          `json:"wallets"`                // 参与统计的地址数 (含余额为 0)
+	Failed       int          `json:"failed"`                 // 查询失败的地址数
+	HolderCount  int          `json:"holder_count"`           // 余额大于 0 的地址数
+	Total        *big.Float   `json:"total"`                  // 持有人余额合计
+	TotalSupply  *big.Float   `json:"total_supply,omitempty"` // 代币总供应量，未知时为空
+	Top          []TopHolder  `json:"top"`
+	Gini         float64      `json:"gini"`
+	Mean         *big.Float   `json:"mean"`
+	Median       *big.Float   `json:"median"`
+	Percentiles  []Percentile `json:"percentiles"`
+	Distribution []Bucket     `json:"distribution"`
+}
+
+// TopHolder 头部持有人
+type TopHolder struct {
+	Rank        int            `json:"rank"`
+	Owner       common.Address `json:"owner"`
+	Balance     *big.Float     `json:"balance"`
+	PctOfSupply float64        `json:"pct_of_supply,omitempty"` // 占总供应量的百分比，总供应量未知时为 0
+	PctOfHeld   float64        `json:"pct_of_held"`             // 占本次统计合计的百分比
+}
+
+// Percentile 百分位余额
+type Percentile struct {
+	P     int        `json:"p"`
+	Value *big.Float `json:"value"`
+}
+
+// Bucket 余额区间直方图的一个桶，区间为 [Min, Max)
+type Bucket struct {
+	Label string     `json:"label"`
+	Min   float64    `json:"min"`
+	Max   float64    `json:"max"`
+	Count int        `json:"count"`
+	Sum   *big.Float `json:"sum"`
+}
+
+var percentilePoints = []int{10, 25, 75, 90, 99}
+
+// ComputeStats 计算持有人分布统计。totalSupply 为 nil 时不计算占总供应量的百分比。
+func ComputeStats(balances []core.TokenBalance, totalSupply *big.Float, topN int) *Stats {
+	if topN <= 0 {
+		topN = DefaultTopN
+	}
+	st := &Stats{
+		Wallets:     len(balances),
+		Total:       newFloat(),
+		TotalSupply: totalSupply,
+		Mean:        newFloat(),
+		Median:      newFloat(),
+	}
+
+	var holders []core.TokenBalance
+	for _, tb := range balances {
+		if !tb.Success {
+			st.Failed++
+			continue
+		}
+		if tb.Balance != nil && tb.Balance.Sign() > 0 {
+			holders = append(holders, tb)
+			st.Total.Add(st.Total, tb.Balance)
+		}
+	}
+	st.HolderCount = len(holders)
+	if len(holders) == 0 {
+		return st
+	}
+
+	// 按余额从小到大排序，方便算分位数和基尼系数
+	sort.SliceStable(holders, func(i, j int) bool {
+		return holders[i].Balance.Cmp(holders[j].Balance) < 0
+	})
+
+	n := len(holders)
+	st.Mean.Quo(st.Total, newFloat().SetInt64(int64(n)))
+	st.Median = percentile(holders, 50)
+	for _, p := range percentilePoints {
+		st.Percentiles = append(st.Percentiles, Percentile{P: p, Value: percentile(holders, p)})
+	}
+	st.Gini = gini(holders, st.Total)
+	st.Distribution = histogram(holders)
+
+	for i := 0; i < topN && i < n; i++ {
+		tb := holders[n-1-i]
+		th := TopHolder{
+			Rank:      i + 1,
+			Owner:     tb.Owner,
+			Balance:   tb.Balance,
+			PctOfHeld: Pct(tb.Balance, st.Total),
+		}
+		if totalSupply != nil && totalSupply.Sign() > 0 {
+			th.PctOfSupply = Pct(tb.Balance, totalSupply)
+		}
+		st.Top = append(st.Top, th)
+	}
+	return st
+}
+
+// percentile 最近秩法取第 p 百分位，sorted 需按余额升序
+func percentile(sorted []core.TokenBalance, p int) *big.Float {
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1].Balance
+}
+
+// gini 基尼系数: G = 2*Σ(i*x_i) / (n*Σx) - (n+1)/n，i 从 1 开始，sorted 需按余额升序
+func gini(sorted []core.TokenBalance, total *big.Float) float64 {
+	n := len(sorted)
+	if n < 2 || total.Sign() == 0 {
+		return 0
+	}
+	weighted := newFloat()
+	for i, tb := range sorted {
+		term := newFloat().Mul(newFloat().SetInt64(int64(i+1)), tb.Balance)
+		weighted.Add(weighted, term)
+	}
+	denom := newFloat().Mul(newFloat().SetInt64(int64(n)), total)
+	ratio, _ := newFloat().Quo(weighted, denom).Float64()
+	return 2*ratio - float64(n+1)/float64(n)
+}
+
+// histogram 按 10 的幂划分余额区间，sorted 需按余额升序
+func histogram(sorted []core.TokenBalance) []Bucket {
+	var buckets []Bucket
+	index := make(map[int]int) // 指数 -> buckets 下标
+	for _, tb := range sorted {
+		v, _ := tb.Balance.Float64()
+		exp := int(math.Floor(math.Log10(v)))
+		i, ok := index[exp]
+		if !ok {
+			lo, hi := math.Pow10(exp), math.Pow10(exp+1)
+			buckets = append(buckets, Bucket{
+				Label: fmt.Sprintf("[%g, %g)", lo, hi),
+				Min:   lo,
+				Max:   hi,
+				Sum:   newFloat(),
+			})
+			i = len(buckets) - 1
+			index[exp] = i
+		}
+		buckets[i].Count++
+		buckets[i].Sum.Add(buckets[i].Sum, tb.Balance)
+	}
+	return buckets
+}
+
+// Pct part 占 whole 的百分比，whole 为 0 时返回 0
+func Pct(part, whole *big.Float) float64 {
+	if whole.Sign() == 0 {
+		return 0
+	}
+	r, _ := newFloat().Quo(part, whole).Float64()
+	return r * 100
+}
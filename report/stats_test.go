@@ -0,0 +1,52 @@
+package report
+
+import (
+	"chain-lens/core"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestComputeStats(t *testing.T) {
+	var balances []core.TokenBalance
+	for i, v := range []int64{3, 0, 1, 400, 2, 40} {
+		balances = append(balances, core.TokenBalance{
+			Owner:   common.BigToAddress(big.NewInt(int64(i + 1))),
+			Balance: big.NewFloat(float64(v)),
+			Success: true,
+		})
+	}
+	balances = append(balances, core.TokenBalance{Owner: common.HexToAddress("0xff")})
+
+	st := ComputeStats(balances, big.NewFloat(1000), 2)
+
+	if st.Wallets != 7 || st.Failed != 1 || st.HolderCount != 5 {
+		t.Fatalf("unexpected counts: %+v", st)
+	}
+	if st.Total.Text('f', -1) != "446" {
+		t.Fatalf("expected total 446, got %s", st.Total.Text('f', -1))
+	}
+	if len(st.Top) != 2 || st.Top[0].Balance.Text('f', -1) != "400" || st.Top[0].PctOfSupply != 40 {
+		t.Fatalf("unexpected top holders: %+v", st.Top)
+	}
+	if st.Median.Text('f', -1) != "3" {
+		t.Fatalf("expected median 3, got %s", st.Median.Text('f', -1))
+	}
+	// 1,2,3 在 [1,10)，40 在 [10,100)，400 在 [100,1000)
+	if len(st.Distribution) != 3 || st.Distribution[0].Count != 3 || st.Distribution[2].Count != 1 {
+		t.Fatalf("unexpected distribution: %+v", st.Distribution)
+	}
+}
+
+func TestGini(t *testing.T) {
+	equal := []core.TokenBalance{{Balance: big.NewFloat(5)}, {Balance: big.NewFloat(5)}, {Balance: big.NewFloat(5)}}
+	if g := gini(equal, big.NewFloat(15)); math.Abs(g) > 1e-9 {
+		t.Fatalf("expected gini 0 for equal balances, got %f", g)
+	}
+	sorted := []core.TokenBalance{{Balance: big.NewFloat(1)}, {Balance: big.NewFloat(2)}, {Balance: big.NewFloat(3)}, {Balance: big.NewFloat(4)}}
+	if g := gini(sorted, big.NewFloat(10)); math.Abs(g-0.25) > 1e-9 {
+		t.Fatalf("expected gini 0.25, got %f", g)
+	}
+}
@@ -0,0 +1,28 @@
+package report
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Summary 一次运行结束时的汇总，终端打印和 webhook 通知使用同一份数据
+type Summary struct {
+	ChainID      int64          `json:"chain_id"`
+	Network      string         `json:"network"`
+	Block        uint64         `json:"block"`
+	TokenAddress common.Address `json:"token_address"`
+	Symbol       string         `json:"symbol"`
+	WalletCount  int            `json:"wallet_count"`
+	SuccessCount int            `json:"success_count"`
+	TotalBalance *big.Float     `json:"total_balance"`
+	TotalUSD     *big.Float     `json:"total_usd,omitempty"` // 配置了报价路由时的美元价值
+	StartedAt    time.Time      `json:"started_at"`
+	Duration     time.Duration  `json:"duration_ns"`
+}
+
+// FailedCount 查询失败的钱包数
+func (s *Summary) FailedCount() int {
+	return s.WalletCount - s.SuccessCount
+}
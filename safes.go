@@ -0,0 +1,179 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/modules/safe"
+	"chain-lens/report"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// safeEntry safes 子命令里单个 Safe 的报告
+type safeEntry struct {
+	safe.Info
+	Balances []safeBalance `json:"balances,omitempty"`
+}
+
+type safeBalance struct {
+	Token   common.Address `json:"token"`
+	Symbol  string         `json:"symbol"`
+	Balance string         `json:"balance"`
+	Success bool           `json:"success"`
+}
+
+// safesReport safes 子命令输出的 JSON 结构
+type safesReport struct {
+	ChainID int64       `json:"chain_id"`
+	Block   uint64      `json:"block"`
+	Safes   []safeEntry `json:"safes"`
+	NotSafe []string    `json:"not_safe,omitempty"`
+}
+
+// runSafes safes 子命令：读取每个 Safe 的 owners、threshold、nonce、版本，并可选查询金库余额
+//
+//	chain-lens safes -file treasury.txt -tokens 0xUSDC,0xWETH -native
+func runSafes(args []string) {
+	fs := flag.NewFlagSet("safes", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	filePath := fs.String("file", "wallets.txt", "包含 Safe 地址的文件路径 (每行一个)")
+	tokensFlag := fs.String("tokens", "", "逗号分隔的 ERC20 代币地址，查询每个 Safe 的余额")
+	withNative := fs.Bool("native", false, "同时查询原生币余额")
+	batch := fs.Int("batch", core.DefaultBatchSize, "每次 Multicall 打包的调用数")
+	outPath := fs.String("out", "", "把结果写成 JSON 文件")
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	cfg := loadConfig(*configPath)
+	addresses, err := loadAddresses(*filePath)
+	if err != nil {
+		fatal(i18n.Errorf("err.read_file", err))
+	}
+	tokens, err := parseAddressList(*tokensFlag)
+	if err != nil {
+		fatal(err)
+	}
+	assets := cfg.SafeAssets
+	for _, token := range tokens {
+		assets = append(assets, AssetConfig{TokenAddress: token.Hex(), TokenType: "erc20"})
+	}
+	if *withNative {
+		assets = append(assets, AssetConfig{TokenType: "native"})
+	}
+	for _, asset := range assets {
+		if _, err := ParseTokenType(asset.TokenType); err != nil {
+			fatal(err)
+		}
+	}
+	logger.Info("addresses loaded", "count", len(addresses))
+
+	client, err := connect(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	defer client.Close()
+
+	startTime := time.Now()
+	blockNumber, err := client.Client.BlockNumber(context.Background())
+	if err != nil {
+		fatal(i18n.Errorf("err.block_number", err))
+	}
+	block := new(big.Int).SetUint64(blockNumber)
+	mc, err := newMultiChecker(cfg, client, block)
+	if err != nil {
+		fatal(err)
+	}
+	infos, err := safe.Detect(mc, addresses, *batch)
+	if err != nil {
+		fatal(i18n.Errorf("err.safes", err))
+	}
+
+	rep := safesReport{ChainID: client.ChainID.Int64(), Block: blockNumber}
+	var safeAddrs []common.Address
+	for _, info := range infos {
+		if !info.IsSafe {
+			rep.NotSafe = append(rep.NotSafe, info.Address.Hex())
+			continue
+		}
+		rep.Safes = append(rep.Safes, safeEntry{Info: info})
+		safeAddrs = append(safeAddrs, info.Address)
+	}
+
+	// 可选：金库余额，每个资产一次批量查询
+	totals := make([]*big.Float, len(assets))
+	symbols := make([]string, len(assets))
+	if len(safeAddrs) > 0 {
+		for j, asset := range assets {
+			assetCfg := cfg
+			assetCfg.TokenAddress = asset.TokenAddress
+			assetCfg.TokenType = asset.TokenType
+			balances := collectBalances(assetCfg, client, safeAddrs, block)
+			totals[j] = new(big.Float)
+			symbols[j] = asset.Symbol
+			for i, tb := range balances {
+				if symbols[j] == "" {
+					symbols[j] = tb.Symbol
+				}
+				sb := safeBalance{Token: common.HexToAddress(asset.TokenAddress), Symbol: tb.Symbol, Success: tb.Success}
+				if tb.Success && tb.Balance != nil {
+					sb.Balance = tb.Balance.Text('f', -1)
+					totals[j].Add(totals[j], tb.Balance)
+				}
+				rep.Safes[i].Balances = append(rep.Safes[i].Balances, sb)
+			}
+		}
+	}
+
+	for i, s := range rep.Safes {
+		line := fmt.Sprintf("🔐 [%d] %s | %d/%d | nonce %d", i+1, s.Address.Hex(), s.Threshold, len(s.Owners), s.Nonce)
+		if s.Version != "" {
+			line += " | v" + s.Version
+		}
+		if s.Singleton == nil {
+			line += i18n.T("progress.safes.not_proxy")
+		}
+		fmt.Println(line)
+		owners := make([]string, len(s.Owners))
+		for k, o := range s.Owners {
+			owners[k] = o.Hex()
+		}
+		fmt.Println(i18n.T("progress.safes.owners", strings.Join(owners, ", ")))
+		for _, b := range s.Balances {
+			if !b.Success {
+				fmt.Println(i18n.T("progress.safes.failed", b.Symbol))
+				continue
+			}
+			fmt.Printf("   💰 %s %s\n", fmt.Sprintf("%.4f", report.ParseBalance(b.Balance)), b.Symbol)
+		}
+	}
+	for _, addr := range rep.NotSafe {
+		fmt.Println(i18n.T("progress.safes.not_safe", addr))
+	}
+
+	fmt.Printf("\n--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.safes.title", blockNumber))
+	fmt.Printf("--------------------------------------------------\n")
+	fmt.Println(i18n.T("report.safes.count", len(rep.Safes), len(addresses)))
+	for j := range assets {
+		if totals[j] != nil {
+			fmt.Println(i18n.T("report.safes.total", totals[j], symbols[j]))
+		}
+	}
+	fmt.Println(i18n.T("report.safes.time", time.Since(startTime)))
+	fmt.Printf("--------------------------------------------------\n")
+
+	if *outPath != "" {
+		if err := report.WriteJSON(*outPath, rep); err != nil {
+			logger.Warn("failed to write report", "path", *outPath, "err", err)
+		} else {
+			logger.Info("report written", "path", *outPath)
+		}
+	}
+}
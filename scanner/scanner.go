@@ -0,0 +1,400 @@
+// Package scanner 对外提供的余额查询引擎：按批拆分钱包列表，在 RPC 连接池上并发执行
+// Multicall (或其他批量方式)，批量失败的钱包再逐个补查。CLI 的默认命令、serve 和 grpc 都基于它。
+//
+// 库代码不会退出进程，所有错误都通过返回值交给调用方。
+package scanner
+
+import (
+	"chain-lens/core"
+	"chain-lens/metrics"
+	"chain-lens/modules/erc20"
+	"chain-lens/modules/erc721"
+	"chain-lens/modules/multicall"
+	"chain-lens/modules/native"
+	"chain-lens/progress"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	DefaultConcurrency      = 4  // 默认同时执行的批次数
+	DefaultRetryConcurrency = 20 // 默认逐个补查的并发数，防止把 RPC 节点打挂
+)
+
+// Asset 要查询的资产
+type Asset struct {
+	Type    multicall.TokenType
+	Address common.Address // 代币合约地址，原生币忽略
+}
+
+// Options Scanner 的配置，零值字段使用默认值
+type Options struct {
+	Pool             *core.ClientPool   // 必填：同一条链的 RPC 连接池，批次按轮询分配到各个连接
+	Assets           []Asset            // 要查询的资产，每个资产单独出结果
+	Block            *big.Int           // 固定查询的区块高度，nil 表示每次扫描开始时取最新区块
+	Concurrency      int                // 同时执行的批次数，默认 DefaultConcurrency
+	BatchSize        int                // 每批的钱包数，默认 core.DefaultBatchSize
+	RetryConcurrency int                // 逐个补查的并发数，默认 DefaultRetryConcurrency
+	Strategy         multicall.Strategy // 批量方式，空表示 auto
+	Logger           core.Logger        // 诊断日志，nil 时沿用各连接的日志器
+	// OnProgress 进度回调：每次批量调用、逐个补查、批次完成后调用 (串行，应当尽快返回)。
+	// 可以直接传 progress.Reporter 的 Update。
+	OnProgress func(progress.State)
+}
+
+// Batch 一批钱包在一个资产上的查询结果 (已经过补查)
+type Batch struct {
+	Asset      Asset
+	AssetIndex int // Asset 在 Options.Assets 里的下标
+	ChainID    int64
+	Block      uint64
+	Offset     int                 // 这批第一个钱包在输入列表里的下标
+	Balances   []core.TokenBalance // 与 wallets[Offset:Offset+len(Balances)] 一一对应
+	Retried    int                 // 批量失败后逐个补查的钱包数
+}
+
+// Result 一个资产的完整结果
+type Result struct {
+	Asset    Asset
+	ChainID  int64
+	Block    uint64
+	Balances []core.TokenBalance // 与输入的钱包顺序一致，查询失败的 Success 为 false
+}
+
+// Scanner 余额查询引擎。每个连接的 MultiChecker 只创建一次，批量方式在第一次查询时探测，
+// 之后 AtBlock / WithAssets 得到的副本共用探测结果，适合长期运行的服务。
+type Scanner struct {
+	opts     Options
+	checkers map[*core.EvmClient]*multicall.MultiChecker
+}
+
+// New 校验配置并为连接池里的每个连接创建 MultiChecker (不发起网络请求)
+func New(opts Options) (*Scanner, error) {
+	if opts.Pool == nil || len(opts.Pool.Clients) == 0 {
+		return nil, errors.New("scanner: client pool is empty")
+	}
+	if _, err := multicall.ParseStrategy(string(opts.Strategy)); err != nil {
+		return nil, err
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = core.DefaultBatchSize
+	}
+	if opts.RetryConcurrency <= 0 {
+		opts.RetryConcurrency = DefaultRetryConcurrency
+	}
+	s := &Scanner{opts: opts, checkers: make(map[*core.EvmClient]*multicall.MultiChecker, len(opts.Pool.Clients))}
+	for _, client := range opts.Pool.Clients {
+		mc, err := multicall.NewMultiCheckerForChain(client.Client, client.Chain)
+		if err != nil {
+			return nil, fmt.Errorf("scanner: %w", err)
+		}
+		mc.Strategy = opts.Strategy
+		mc.Logger = s.logger(client)
+		s.checkers[client] = mc
+	}
+	return s, nil
+}
+
+// Options 返回补全默认值之后的配置
+func (s *Scanner) Options() Options {
+	return s.opts
+}
+
+// AtBlock 返回固定在另一个区块上的副本 (nil 表示 latest)，共用已探测的批量方式
+func (s *Scanner) AtBlock(block *big.Int) *Scanner {
+	c := *s
+	c.opts.Block = block
+	return &c
+}
+
+// WithAssets 返回查询另一组资产的副本
+func (s *Scanner) WithAssets(assets ...Asset) *Scanner {
+	c := *s
+	c.opts.Assets = assets
+	return &c
+}
+
+// WithBatching 返回使用另一组批次大小和并发数的副本，非正数表示沿用当前值
+func (s *Scanner) WithBatching(batchSize, concurrency int) *Scanner {
+	c := *s
+	if batchSize > 0 {
+		c.opts.BatchSize = batchSize
+	}
+	if concurrency > 0 {
+		c.opts.Concurrency = concurrency
+	}
+	return &c
+}
+
+// WithProgress 返回使用另一个进度回调的副本，nil 表示不统计进度
+func (s *Scanner) WithProgress(fn func(progress.State)) *Scanner {
+	c := *s
+	c.opts.OnProgress = fn
+	return &c
+}
+
+// Checker 返回 client 对应、固定在当前区块上的 MultiChecker，用于代币识别等其他批量调用
+func (s *Scanner) Checker(client *core.EvmClient) *multicall.MultiChecker {
+	mc, ok := s.checkers[client]
+	if !ok {
+		return nil
+	}
+	return mc.AtBlock(s.opts.Block)
+}
+
+// Head 返回本次扫描固定的区块高度：配置了 Block 时直接使用，否则取最新区块
+func (s *Scanner) Head(ctx context.Context) (uint64, error) {
+	if s.opts.Block != nil {
+		return s.opts.Block.Uint64(), nil
+	}
+	head, err := s.opts.Pool.Next().Client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get block number: %w", err)
+	}
+	return head, nil
+}
+
+// Scan 查询所有资产上所有钱包的余额，按 Assets 的顺序返回，每个结果里的余额与 wallets 顺序一致。
+// 单个钱包查询失败不算错误 (Success 为 false)；只有取区块高度失败或 ctx 取消时返回错误。
+func (s *Scanner) Scan(ctx context.Context, wallets []common.Address) ([]Result, error) {
+	results := make([]Result, len(s.opts.Assets))
+	for i, asset := range s.opts.Assets {
+		results[i] = Result{Asset: asset, ChainID: s.chainID(), Balances: make([]core.TokenBalance, len(wallets))}
+	}
+	err := s.Stream(ctx, wallets, func(b Batch) {
+		r := &results[b.AssetIndex]
+		r.Block = b.Block
+		copy(r.Balances[b.Offset:], b.Balances)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Stream 与 Scan 相同，但每完成一批就调用一次 handle (调用顺序即完成顺序，不会并发调用)。
+// 适合边查边推送的场景，例如 gRPC 流式接口。
+func (s *Scanner) Stream(ctx context.Context, wallets []common.Address, handle func(Batch)) error {
+	if len(s.opts.Assets) == 0 {
+		return errors.New("scanner: no assets to scan")
+	}
+	head, err := s.Head(ctx)
+	if err != nil {
+		return err
+	}
+	run := &scan{Scanner: s, ctx: ctx, block: new(big.Int).SetUint64(head), fallbacks: map[fallbackKey]core.AssetChecker{}}
+	if s.opts.OnProgress != nil {
+		batches := (len(wallets) + s.opts.BatchSize - 1) / s.opts.BatchSize
+		run.tracker = progress.NewTracker(len(s.opts.Assets)*batches, len(s.opts.Assets)*len(wallets), s.opts.OnProgress)
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex // 保证 handle 串行调用
+		sem = make(chan struct{}, s.opts.Concurrency)
+	)
+	dispatch := func(assetIndex int, offset int, chunk []common.Address) bool {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			batch := run.batch(assetIndex, offset, chunk)
+			run.tracker.BatchDone(len(batch.Balances), countFailed(batch.Balances))
+			mu.Lock()
+			defer mu.Unlock()
+			handle(batch)
+		}()
+		return true
+	}
+
+loop:
+	for i := range s.opts.Assets {
+		for offset := 0; offset < len(wallets); offset += s.opts.BatchSize {
+			end := min(offset+s.opts.BatchSize, len(wallets))
+			if !dispatch(i, offset, wallets[offset:end]) {
+				break loop
+			}
+		}
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func countFailed(balances []core.TokenBalance) int {
+	n := 0
+	for _, b := range balances {
+		if !b.Success {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Scanner) chainID() int64 {
+	if s.opts.Pool.ChainID == nil {
+		return 0
+	}
+	return s.opts.Pool.ChainID.Int64()
+}
+
+func (s *Scanner) logger(client *core.EvmClient) core.Logger {
+	if s.opts.Logger != nil {
+		return s.opts.Logger
+	}
+	return client.Log()
+}
+
+// scan 一次 Stream 调用的状态：请求的 ctx、固定的区块、进度计数和按连接、资产缓存的补查器
+type scan struct {
+	*Scanner
+	ctx       context.Context // 已发出的批次和补查都随它取消
+	block     *big.Int
+	tracker   *progress.Tracker // 没有 OnProgress 时为 nil
+	mu        sync.Mutex
+	fallbacks map[fallbackKey]core.AssetChecker
+}
+
+type fallbackKey struct {
+	client *core.EvmClient
+	asset  Asset
+}
+
+// batch 批量查询一批钱包，整批失败或个别失败的钱包再逐个补查
+func (r *scan) batch(assetIndex int, offset int, wallets []common.Address) Batch {
+	client := r.opts.Pool.Next()
+	log := r.logger(client)
+	asset := r.opts.Assets[assetIndex]
+	b := Batch{Asset: asset, AssetIndex: assetIndex, ChainID: r.chainID(), Block: r.block.Uint64(), Offset: offset}
+
+	mc := r.checkers[client].AtBlock(r.block).WithContext(r.ctx)
+	if r.tracker != nil {
+		mc.OnBatch = r.tracker.Calls
+	}
+	balances, err := mc.CheckToken(asset.Type, asset.Address, wallets)
+	var retry []int
+	if err != nil {
+		// 整批失败 (比如节点不支持，或者合约报错)：所有钱包都逐个补查
+		log.Warn("batch query failed, retrying every wallet individually", "offset", offset, "wallets", len(wallets), "err", err)
+		balances = make([]core.TokenBalance, len(wallets))
+		for i, w := range wallets {
+			balances[i] = core.TokenBalance{TokenAddress: asset.Address, Owner: w}
+			retry = append(retry, i)
+		}
+	} else {
+		for i, tb := range balances {
+			if !tb.Success {
+				retry = append(retry, i)
+			}
+		}
+	}
+	b.Balances = balances
+	b.Retried = len(retry)
+	if len(retry) == 0 {
+		return b
+	}
+
+	if r.ctx.Err() != nil {
+		return b
+	}
+	log.Info("retrying failed wallets", "offset", offset, "count", len(retry))
+	checker, err := r.fallback(client, asset)
+	if err != nil {
+		log.Warn("fallback checker unavailable", "err", err)
+		return b
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, r.opts.RetryConcurrency)
+retries:
+	for _, i := range retry {
+		// ctx 结束后不再发起新的补查
+		select {
+		case sem <- struct{}{}:
+		case <-r.ctx.Done():
+			break retries
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			single, err := checker.BalanceOf(wallets[i])
+			metrics.ObserveRetry(err == nil)
+			r.tracker.Retried()
+			if err != nil {
+				log.Warn("retry failed", "index", offset+i, "wallet", wallets[i], "err", err)
+				return
+			}
+			log.Debug("retry succeeded", "index", offset+i, "wallet", wallets[i])
+			balances[i] = core.TokenBalance{
+				TokenAddress: asset.Address,
+				Owner:        wallets[i],
+				Balance:      single.Balance,
+				Symbol:       single.Symbol,
+				Success:      true,
+			}
+		}(i)
+	}
+	wg.Wait()
+	return b
+}
+
+// fallback 返回 client 上 asset 的逐个查询器，同一次扫描里只创建一次
+func (r *scan) fallback(client *core.EvmClient, asset Asset) (core.AssetChecker, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := fallbackKey{client: client, asset: asset}
+	if c, ok := r.fallbacks[key]; ok {
+		return c, nil
+	}
+	c, err := NewChecker(r.ctx, asset, client, r.block)
+	if err != nil {
+		return nil, err
+	}
+	r.fallbacks[key] = c
+	return c, nil
+}
+
+// NewChecker 创建 asset 的逐个查询器 (每个钱包一次 RPC)，固定在 block 上 (nil 表示 latest)，
+// 查询使用 ctx，ctx 结束后不再等待节点
+func NewChecker(ctx context.Context, asset Asset, client *core.EvmClient, block *big.Int) (core.AssetChecker, error) {
+	switch asset.Type {
+	case multicall.TokenTypeERC20:
+		c, err := erc20.NewChecker(asset.Address, client)
+		if err != nil {
+			return nil, err
+		}
+		c.BlockNumber = block
+		c.Context = ctx
+		return c, nil
+	case multicall.TokenTypeERC721:
+		c, err := erc721.NewChecker(asset.Address, client)
+		if err != nil {
+			return nil, err
+		}
+		c.BlockNumber = block
+		c.Context = ctx
+		return c, nil
+	case multicall.TokenTypeNative:
+		c, err := native.NewChecker(client)
+		if err != nil {
+			return nil, err
+		}
+		c.BlockNumber = block
+		c.Context = ctx
+		return c, nil
+	default:
+		return nil, fmt.Errorf("unknown token type: %d", asset.Type)
+	}
+}
@@ -0,0 +1,214 @@
+package scanner
+
+import (
+	"bytes"
+	"chain-lens/core"
+	"chain-lens/modules/multicall"
+	"chain-lens/progress"
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeNode 进程内的假节点：余额 = 地址最后一个字节 (ETH)
+type fakeNode struct{}
+
+func (fakeNode) ChainId() *hexutil.Big       { return (*hexutil.Big)(big.NewInt(31337)) }
+func (fakeNode) BlockNumber() hexutil.Uint64 { return 100 }
+
+func (fakeNode) GetBalance(addr common.Address, block string) *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int).Mul(big.NewInt(int64(addr[19])), big.NewInt(1e18)))
+}
+
+// flakyNode 每个余额为偶数的地址第一次查询失败，第二次成功
+type flakyNode struct {
+	fakeNode
+	mu   sync.Mutex
+	seen map[common.Address]bool
+}
+
+func (f *flakyNode) GetBalance(addr common.Address, block string) (*hexutil.Big, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if addr[19]%2 == 0 && !f.seen[addr] {
+		f.seen[addr] = true
+		return nil, errors.New("header not found")
+	}
+	return f.fakeNode.GetBalance(addr, block), nil
+}
+
+// revertingToken 所有 eth_call 都 revert，ERC20 的 decimals() 查不到
+type revertingToken struct{ fakeNode }
+
+func (revertingToken) Call(msg map[string]any, block string) (hexutil.Bytes, error) {
+	return nil, errors.New("execution reverted")
+}
+
+// rejectBatches 模拟不支持 JSON-RPC 批量请求的节点：请求体是数组时返回 500
+func rejectBatches(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+			http.Error(w, "batch requests are not supported", http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newTestPool(t *testing.T) *core.ClientPool {
+	return newPool(t, fakeNode{}, nil)
+}
+
+// newPool 用 service 作为 eth 命名空间启动假节点，wrap 不为空时包装节点的 HTTP handler
+func newPool(t *testing.T, service any, wrap func(http.Handler) http.Handler) *core.ClientPool {
+	node := rpc.NewServer()
+	if err := node.RegisterName("eth", service); err != nil {
+		t.Fatal(err)
+	}
+	var handler http.Handler = node
+	if wrap != nil {
+		handler = wrap(node)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	pool, err := core.NewClientPool([]string{srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	pool.SetChain(core.LookupChain(pool.ChainID, nil))
+	return pool
+}
+
+func TestScan(t *testing.T) {
+	s, err := New(Options{
+		Pool:      newTestPool(t),
+		Assets:    []Asset{{Type: multicall.TokenTypeNative}},
+		BatchSize: 3,
+		Strategy:  multicall.StrategyRPCBatch,
+		Logger:    core.NopLogger(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallets := make([]common.Address, 7)
+	for i := range wallets {
+		wallets[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+
+	var offsets []int
+	err = s.Stream(context.Background(), wallets, func(b Batch) {
+		if b.Block != 100 || b.ChainID != 31337 {
+			t.Errorf("batch pinned to block %d on chain %d", b.Block, b.ChainID)
+		}
+		offsets = append(offsets, b.Offset)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(offsets) != 3 {
+		t.Fatalf("expected 3 batches, got offsets %v", offsets)
+	}
+
+	var last progress.State
+	results, err := s.WithProgress(func(st progress.State) { last = st }).Scan(context.Background(), wallets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Done() || last.TotalBatches != 3 || last.Wallets != 7 || last.Calls != 7 || last.Failed != 0 {
+		t.Errorf("unexpected final progress: %+v", last)
+	}
+	for i, tb := range results[0].Balances {
+		if !tb.Success || tb.Owner != wallets[i] {
+			t.Fatalf("balance %d: %+v", i, tb)
+		}
+		if got, _ := tb.Balance.Int64(); got != int64(i+1) {
+			t.Errorf("balance %d = %s, want %d", i, tb.Balance, i+1)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.AtBlock(big.NewInt(50)).Scan(ctx, wallets); err != context.Canceled {
+		t.Fatalf("cancelled scan must return context.Canceled, got %v", err)
+	}
+	if _, err := New(Options{}); err == nil {
+		t.Fatal("empty pool must be rejected")
+	}
+}
+
+func TestScanRetry(t *testing.T) {
+	wallets := make([]common.Address, 7)
+	for i := range wallets {
+		wallets[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	scan := func(pool *core.ClientPool, asset Asset) ([]core.TokenBalance, int) {
+		t.Helper()
+		s, err := New(Options{
+			Pool:      pool,
+			Assets:    []Asset{asset},
+			BatchSize: 3,
+			Strategy:  multicall.StrategyRPCBatch,
+			Logger:    core.NopLogger(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		balances := make([]core.TokenBalance, len(wallets))
+		retried := 0
+		err = s.Stream(context.Background(), wallets, func(b Batch) {
+			copy(balances[b.Offset:], b.Balances)
+			retried += b.Retried
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return balances, retried
+	}
+	checkAll := func(name string, balances []core.TokenBalance) {
+		t.Helper()
+		for i, tb := range balances {
+			if got, _ := tb.Balance.Int64(); !tb.Success || tb.Owner != wallets[i] || got != int64(i+1) {
+				t.Errorf("%s: balance %d = %+v", name, i, tb)
+			}
+		}
+	}
+	native := Asset{Type: multicall.TokenTypeNative}
+
+	// 批量请求里个别钱包失败：只补查失败的 3 个 (余额 2、4、6)
+	balances, retried := scan(newPool(t, &flakyNode{seen: map[common.Address]bool{}}, nil), native)
+	checkAll("partial failure", balances)
+	if retried != 3 {
+		t.Errorf("partial failure: retried %d wallets, want 3", retried)
+	}
+
+	// 节点拒绝整个批量请求：每个钱包都逐个补查
+	balances, retried = scan(newPool(t, fakeNode{}, rejectBatches), native)
+	checkAll("batch failure", balances)
+	if retried != len(wallets) {
+		t.Errorf("batch failure: retried %d wallets, want %d", retried, len(wallets))
+	}
+
+	// decimals() revert：批量查询失败，逐个查询器也创建不了，钱包保持失败状态而不是报错中断
+	token := Asset{Type: multicall.TokenTypeERC20, Address: common.HexToAddress("0x70")}
+	balances, retried = scan(newPool(t, revertingToken{}, nil), token)
+	if retried != len(wallets) {
+		t.Errorf("fallback unavailable: retried %d wallets, want %d", retried, len(wallets))
+	}
+	for i, tb := range balances {
+		if tb.Success || tb.Owner != wallets[i] || tb.TokenAddress != token.Address {
+			t.Errorf("fallback unavailable: balance %d = %+v", i, tb)
+		}
+	}
+}
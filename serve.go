@@ -0,0 +1,168 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/metrics"
+	"chain-lens/modules/multicall"
+	"chain-lens/report"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// balancesRequest POST /v1/balances 的请求体，token 为空时使用配置文件里的代币
+type balancesRequest struct {
+	Wallets      []string `json:"wallets"`
+	TokenAddress string   `json:"token_address,omitempty"`
+	TokenType    string   `json:"token_type,omitempty"`
+	Block        uint64   `json:"block,omitempty"` // 0 表示最新区块
+}
+
+// apiServer serve 子命令的 HTTP 服务，所有请求共用一个查询引擎 (包括它的资源限制)
+type apiServer struct {
+	*engine
+}
+
+// runServe serve 子命令：以 HTTP 服务的形式提供余额查询
+//
+//	chain-lens serve -addr :8080
+//	curl -X POST localhost:8080/v1/balances -d '{"wallets":["0x..."],"token_type":"native"}'
+//	curl localhost:8080/v1/balances/0x...?token_type=erc20&token_address=0x...
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	addr := fs.String("addr", ":8080", "监听地址")
+	lim := addLimitFlags(fs)
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	cfg := loadConfig(*configPath)
+	pool := connectPool(cfg)
+	defer pool.Close()
+	srv, err := newAPIServer(cfg, pool, *lim)
+	if err != nil {
+		fatal(err)
+	}
+	logger.Info("http serving", "chain", pool.Clients[0].Chain.Name, "chain_id", pool.ChainID, "rpc", len(pool.Clients), "addr", *addr)
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           srv.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fatal(httpServer.ListenAndServe())
+}
+
+func newAPIServer(cfg Config, pool *core.ClientPool, l limits) (*apiServer, error) {
+	e, err := newEngine(cfg, pool, l)
+	if err != nil {
+		return nil, err
+	}
+	return &apiServer{engine: e}, nil
+}
+
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("POST /v1/balances", s.handleBalances)
+	mux.HandleFunc("GET /v1/balances/{wallet}", s.handleWallet)
+	return mux
+}
+
+func (s *apiServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "chain_id": s.pool.ChainID.Int64()})
+}
+
+func (s *apiServer) handleBalances(w http.ResponseWriter, r *http.Request) {
+	var req balancesRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 8<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+		return
+	}
+	s.serveQuery(w, r, req)
+}
+
+func (s *apiServer) handleWallet(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := balancesRequest{
+		Wallets:      []string{r.PathValue("wallet")},
+		TokenAddress: q.Get("token_address"),
+		TokenType:    q.Get("token_type"),
+	}
+	if b := q.Get("block"); b != "" {
+		block, err := strconv.ParseUint(b, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid block: %s", b))
+			return
+		}
+		req.Block = block
+	}
+	s.serveQuery(w, r, req)
+}
+
+// serveQuery 校验请求、排队拿并发令牌，在超时时间内完成查询并返回快照
+func (s *apiServer) serveQuery(w http.ResponseWriter, r *http.Request, req balancesRequest) {
+	cfg, addresses, tokenType, err := s.validate(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := s.withTimeout(r.Context())
+	defer cancel()
+	release, err := s.acquire(ctx)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	done := make(chan *report.Snapshot, 1)
+	errc := make(chan error, 1)
+	go func() {
+		// 超时后不再发起新的批次和补查，进行中的 RPC 随 ctx 取消；查询真正结束后才归还令牌，保证并发上限真实有效
+		defer release()
+		snap, err := s.snapshot(ctx, cfg, tokenType, addresses, req.Block)
+		if err != nil {
+			errc <- err
+			return
+		}
+		done <- snap
+	}()
+
+	select {
+	case snap := <-done:
+		writeJSON(w, http.StatusOK, snap)
+	case err := <-errc:
+		writeError(w, http.StatusBadGateway, err)
+	case <-ctx.Done():
+		writeError(w, http.StatusGatewayTimeout, fmt.Errorf("request timed out after %s", s.limits.timeout))
+	}
+}
+
+// validate 检查钱包地址、代币类型和数量限制，返回本次请求使用的配置
+func (s *apiServer) validate(req balancesRequest) (Config, []common.Address, multicall.TokenType, error) {
+	cfg, tokenType, err := s.asset(req.TokenAddress, req.TokenType)
+	if err != nil {
+		return cfg, nil, 0, err
+	}
+	addresses, err := s.wallets(req.Wallets)
+	return cfg, addresses, tokenType, err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
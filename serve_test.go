@@ -0,0 +1,132 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/report"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestServeValidation(t *testing.T) {
+	srv, err := newAPIServer(Config{TokenType: "erc20", TokenAddress: "0xA0b86991C6218B36c1d19D4a2E9Eb0CE3606EB48"}, nil, limits{timeout: time.Second, concurrency: 1, maxWallets: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := srv.routes()
+
+	cases := []struct {
+		method, path, body string
+	}{
+		{"POST", "/v1/balances", `{"wallets":[]}`},
+		{"POST", "/v1/balances", `{"wallets":["0x1","0x2","0x3"]}`},
+		{"POST", "/v1/balances", `{"wallets":["not-an-address"]}`},
+		{"POST", "/v1/balances", `{"wallets":["0x0000000000000000000000000000000000000001"],"token_type":"erc1155"}`},
+		{"POST", "/v1/balances", `{"wallets":["0x0000000000000000000000000000000000000001"],"token_type":"erc20","token_address":"bad"}`},
+		{"POST", "/v1/balances", `{"wallet":"0x0000000000000000000000000000000000000001"}`},
+		{"GET", "/v1/balances/0x0000000000000000000000000000000000000001?block=latest", ""},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"error"`) {
+			t.Fatalf("%s %s %s: expected 400 with error, got %d %s", c.method, c.path, c.body, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// slowNode 余额查询一直挂起，直到请求被取消 (最多 10 秒)
+type slowNode struct {
+	fakeNode
+	inflight atomic.Int32
+}
+
+func (n *slowNode) GetBalance(ctx context.Context, addr common.Address, block string) (*hexutil.Big, error) {
+	n.inflight.Add(1)
+	defer n.inflight.Add(-1)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(10 * time.Second):
+		return nil, errors.New("node too slow")
+	}
+}
+
+// newFakePool 用 service 作为 eth 命名空间启动进程内的假节点，返回连到它的连接池
+func newFakePool(t *testing.T, service any) *core.ClientPool {
+	node := rpc.NewServer()
+	if err := node.RegisterName("eth", service); err != nil {
+		t.Fatal(err)
+	}
+	httpNode := httptest.NewServer(node)
+	t.Cleanup(httpNode.Close)
+	pool, err := core.NewClientPool([]string{httpNode.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	pool.SetChain(core.LookupChain(pool.ChainID, nil))
+	return pool
+}
+
+func TestServeBalances(t *testing.T) {
+	cfg := Config{TokenType: "native", Strategy: "rpc-batch"}
+	srv, err := newAPIServer(cfg, newFakePool(t, fakeNode{}), limits{timeout: 5 * time.Second, concurrency: 1, maxWallets: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := srv.routes()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/balances", strings.NewReader(`{"wallets":["0x0000000000000000000000000000000000000001","0x0000000000000000000000000000000000000003"]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var snap report.Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.ChainID != 31337 || snap.Block != 100 || len(snap.Balances) != 2 || snap.Balances[1].Balance != "3" {
+		t.Fatalf("unexpected snapshot: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/balances/0x0000000000000000000000000000000000000002?block=42", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"block":42`) {
+		t.Fatalf("expected 200 at block 42, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServeTimeout(t *testing.T) {
+	node := &slowNode{}
+	cfg := Config{TokenType: "native", Strategy: "rpc-batch"}
+	srv, err := newAPIServer(cfg, newFakePool(t, node), limits{timeout: 100 * time.Millisecond, concurrency: 1, maxWallets: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, httptest.NewRequest("POST", "/v1/balances", strings.NewReader(`{"wallets":["0x0000000000000000000000000000000000000001","0x0000000000000000000000000000000000000002"]}`)))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// 超时后进行中的 RPC 随 ctx 取消，也不再逐个补查，并发令牌很快归还
+	deadline := time.Now().Add(2 * time.Second)
+	for len(srv.sem) > 0 || node.inflight.Load() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("query still running after timeout: %d slots held, %d rpc calls in flight", len(srv.sem), node.inflight.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/modules/multicall"
+	"chain-lens/report"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// buildStats 在同一个区块上查询总供应量，并计算持有人分布统计
+func buildStats(cfg Config, client *core.EvmClient, block *big.Int, balances []core.TokenBalance) *report.Stats {
+	var totalSupply *big.Float
+	tokenType, err := ParseTokenType(cfg.TokenType)
+	if err == nil && tokenType != multicall.TokenTypeNative {
+		mc, err := newMultiChecker(cfg, client, block)
+		if err == nil {
+			totalSupply, err = mc.TotalSupply(tokenType, common.HexToAddress(cfg.TokenAddress))
+		}
+		if err != nil {
+			logger.Warn("totalSupply unavailable, percentages use the held total", "err", err)
+		}
+	}
+	return report.ComputeStats(balances, totalSupply, cfg.TopN)
+}
+
+func printStats(st *report.Stats, symbol string) {
+	line := strings.Repeat("-", 50)
+	fmt.Printf("\n%s\n%s\n%s\n", line, i18n.T("report.stats.title"), line)
+	fmt.Print(i18n.T("report.stats.holders", st.HolderCount, st.Wallets))
+	if st.Failed > 0 {
+		fmt.Print(i18n.T("report.stats.failed", st.Failed))
+	}
+	fmt.Println()
+	fmt.Println(i18n.T("report.stats.held", st.Total, symbol))
+	if st.TotalSupply != nil {
+		fmt.Println(i18n.T("report.stats.supply", st.TotalSupply, symbol, report.Pct(st.Total, st.TotalSupply)))
+	}
+	if st.HolderCount == 0 {
+		fmt.Println(line)
+		return
+	}
+	fmt.Println(i18n.T("report.stats.gini", st.Gini))
+	fmt.Println(i18n.T("report.stats.mean_median", st.Mean, st.Median, symbol))
+	for _, p := range st.Percentiles {
+		fmt.Printf("   P%-3d          : %.4f\n", p.P, p.Value)
+	}
+
+	fmt.Printf("\n%s\n", i18n.T("report.stats.top", len(st.Top)))
+	fmt.Printf("%-5s %-42s %20s %10s %10s\n", "RANK", "ADDRESS", "BALANCE", "% SUPPLY", "% HELD")
+	for _, th := range st.Top {
+		supply := "-"
+		if st.TotalSupply != nil {
+			supply = fmt.Sprintf("%.2f%%", th.PctOfSupply)
+		}
+		fmt.Printf("%-5d %-42s %20.4f %10s %9.2f%%\n", th.Rank, th.Owner.Hex(), th.Balance, supply, th.PctOfHeld)
+	}
+
+	fmt.Printf("\n%s\n", i18n.T("report.stats.distribution"))
+	maxCount := 0
+	for _, b := range st.Distribution {
+		maxCount = max(maxCount, b.Count)
+	}
+	for _, b := range st.Distribution {
+		bar := strings.Repeat("█", max(1, b.Count*30/maxCount))
+		fmt.Printf("%-22s %6d %s\n", b.Label, b.Count, bar)
+	}
+	fmt.Println(line)
+}
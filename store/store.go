@@ -0,0 +1,251 @@
+package store
+
+import (
+	"chain-lens/core"
+	"chain-lens/report"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	config        TEXT    NOT NULL,
+	chain_id      INTEGER NOT NULL,
+	block         INTEGER NOT NULL,
+	token_address TEXT    NOT NULL,
+	symbol        TEXT    NOT NULL,
+	wallet_count  INTEGER NOT NULL,
+	success_count INTEGER NOT NULL,
+	started_at    INTEGER NOT NULL,
+	duration_ms   INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS balances (
+	run_id        INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	idx           INTEGER NOT NULL,
+	owner         TEXT    NOT NULL,
+	token_address TEXT    NOT NULL,
+	symbol        TEXT    NOT NULL,
+	balance       TEXT    NOT NULL,
+	success       INTEGER NOT NULL,
+	PRIMARY KEY (run_id, idx)
+);
+CREATE INDEX IF NOT EXISTS idx_balances_owner ON balances(owner, token_address);
+`
+
+// Store 本地 SQLite 结果库，记录每次运行及其余额明细
+type Store struct {
+	db *sql.DB
+}
+
+// Run 一次运行的元数据
+type Run struct {
+	ID           int64
+	Config       string // 运行时的配置 (JSON)
+	ChainID      int64
+	Block        uint64 // 查询时固定的区块高度
+	TokenAddress common.Address
+	Symbol       string
+	WalletCount  int
+	SuccessCount int
+	StartedAt    time.Time
+	Duration     time.Duration
+}
+
+// WalletBalance 某个钱包在某次运行中的余额
+type WalletBalance struct {
+	RunID        int64
+	Block        uint64
+	StartedAt    time.Time
+	TokenAddress common.Address
+	Symbol       string
+	Balance      string // 十进制字符串，保证精度不丢
+	Success      bool
+}
+
+// Open 打开 (或创建) path 指向的数据库，并确保表结构存在
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite %s: %w", path, err)
+	}
+	// SQLite 单写者，避免并发写入时 "database is locked"
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("enable foreign keys: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveRun 在一个事务里写入运行记录和全部余额行，返回新运行的 ID
+func (s *Store) SaveRun(run Run, balances []core.TokenBalance) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO runs
+		(config, chain_id, block, token_address, symbol, wallet_count, success_count, started_at, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Config, run.ChainID, run.Block, run.TokenAddress.Hex(), run.Symbol,
+		run.WalletCount, run.SuccessCount, run.StartedAt.Unix(), run.Duration.Milliseconds())
+	if err != nil {
+		return 0, fmt.Errorf("insert run: %w", err)
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO balances
+		(run_id, idx, owner, token_address, symbol, balance, success)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for i, tb := range balances {
+		balance := ""
+		if tb.Balance != nil {
+			balance = tb.Balance.Text('f', -1)
+		}
+		if _, err := stmt.Exec(runID, i, tb.Owner.Hex(), tb.TokenAddress.Hex(), tb.Symbol, balance, tb.Success); err != nil {
+			return 0, fmt.Errorf("insert balance %s: %w", tb.Owner.Hex(), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return runID, nil
+}
+
+// ListRuns 按时间倒序列出最近的 limit 次运行 (limit <= 0 表示全部)
+func (s *Store) ListRuns(limit int) ([]Run, error) {
+	query := `SELECT id, config, chain_id, block, token_address, symbol, wallet_count, success_count, started_at, duration_ms
+		FROM runs ORDER BY id DESC`
+	var args []any
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var (
+			r                Run
+			token            string
+			startedAt, durMs int64
+		)
+		if err := rows.Scan(&r.ID, &r.Config, &r.ChainID, &r.Block, &token, &r.Symbol,
+			&r.WalletCount, &r.SuccessCount, &startedAt, &durMs); err != nil {
+			return nil, err
+		}
+		r.TokenAddress = common.HexToAddress(token)
+		r.StartedAt = time.Unix(startedAt, 0)
+		r.Duration = time.Duration(durMs) * time.Millisecond
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// WalletHistory 查询某个钱包在所有运行中的余额，按运行先后排序。
+// token 为 nil 时返回所有代币的记录。
+func (s *Store) WalletHistory(wallet common.Address, token *common.Address) ([]WalletBalance, error) {
+	query := `SELECT r.id, r.block, r.started_at, b.token_address, b.symbol, b.balance, b.success
+		FROM balances b JOIN runs r ON r.id = b.run_id
+		WHERE b.owner = ?`
+	args := []any{wallet.Hex()}
+	if token != nil {
+		query += " AND b.token_address = ?"
+		args = append(args, token.Hex())
+	}
+	query += " ORDER BY r.id ASC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []WalletBalance
+	for rows.Next() {
+		var (
+			wb        WalletBalance
+			tokenHex  string
+			startedAt int64
+		)
+		if err := rows.Scan(&wb.RunID, &wb.Block, &startedAt, &tokenHex, &wb.Symbol, &wb.Balance, &wb.Success); err != nil {
+			return nil, err
+		}
+		wb.TokenAddress = common.HexToAddress(tokenHex)
+		wb.StartedAt = time.Unix(startedAt, 0)
+		history = append(history, wb)
+	}
+	return history, rows.Err()
+}
+
+// LoadRun 读取某次运行的元数据和全部余额行 (按原始顺序)
+func (s *Store) LoadRun(runID int64) (*Run, []core.TokenBalance, error) {
+	var (
+		r                Run
+		token            string
+		startedAt, durMs int64
+	)
+	err := s.db.QueryRow(`SELECT id, config, chain_id, block, token_address, symbol, wallet_count, success_count, started_at, duration_ms
+		FROM runs WHERE id = ?`, runID).Scan(&r.ID, &r.Config, &r.ChainID, &r.Block, &token, &r.Symbol,
+		&r.WalletCount, &r.SuccessCount, &startedAt, &durMs)
+	if err == sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("run #%d not found", runID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	r.TokenAddress = common.HexToAddress(token)
+	r.StartedAt = time.Unix(startedAt, 0)
+	r.Duration = time.Duration(durMs) * time.Millisecond
+
+	rows, err := s.db.Query(`SELECT owner, token_address, symbol, balance, success
+		FROM balances WHERE run_id = ? ORDER BY idx ASC`, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var balances []core.TokenBalance
+	for rows.Next() {
+		var (
+			tb              core.TokenBalance
+			owner, tokenHex string
+			balance         string
+		)
+		if err := rows.Scan(&owner, &tokenHex, &tb.Symbol, &balance, &tb.Success); err != nil {
+			return nil, nil, err
+		}
+		tb.Owner = common.HexToAddress(owner)
+		tb.TokenAddress = common.HexToAddress(tokenHex)
+		// 与 JSON 快照使用同一个解析函数和精度，保证两种来源的 diff 结果一致
+		tb.Balance = report.ParseBalance(balance)
+		balances = append(balances, tb)
+	}
+	return &r, balances, rows.Err()
+}
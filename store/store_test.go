@@ -0,0 +1,64 @@
+package store
+
+import (
+	"chain-lens/core"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSaveRunAndWalletHistory(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	token := common.HexToAddress("0xA0b86991C6218B36c1d19D4a2E9Eb0CE3606EB48")
+	wallet := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+
+	for i, bal := range []string{"1.5", "1.5", "2.25"} {
+		v, _ := new(big.Float).SetString(bal)
+		run := Run{
+			ChainID:      1,
+			Block:        uint64(100 + i),
+			TokenAddress: token,
+			Symbol:       "USDC",
+			WalletCount:  1,
+			SuccessCount: 1,
+			StartedAt:    time.Unix(int64(1700000000+i), 0),
+			Duration:     1500 * time.Millisecond,
+		}
+		balances := []core.TokenBalance{{Symbol: "USDC", TokenAddress: token, Owner: wallet, Balance: v, Success: true}}
+		if _, err := db.SaveRun(run, balances); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := db.ListRuns(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].Block != 102 || runs[0].Duration != 1500*time.Millisecond {
+		t.Fatalf("unexpected runs: %+v", runs)
+	}
+
+	history, err := db.WalletHistory(wallet, &token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected 3 history rows, got %d", len(history))
+	}
+	if history[0].Balance != "1.5" || history[2].Balance != "2.25" || !history[2].Success {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	other := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	if h, _ := db.WalletHistory(other, nil); len(h) != 0 {
+		t.Fatalf("expected no history for unknown wallet, got %d", len(h))
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/modules/multicall"
+	"chain-lens/modules/pricing"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fetchPrice 在余额所在的同一区块，按配置的报价路由读取代币的美元价格。
+// 失败时只打印警告并返回 nil，不影响余额结果。
+func fetchPrice(cfg Config, client *core.EvmClient, block *big.Int) *pricing.Quote {
+	oracle := newOracle(cfg, client, block)
+	if oracle == nil {
+		return nil
+	}
+	quote, err := oracle.Price(priceToken(cfg))
+	if err != nil {
+		logger.Warn("skipping usd valuation: price lookup failed", "err", err)
+		return nil
+	}
+	fmt.Println(i18n.T("report.price", quote.USD, quote.Source))
+	return quote
+}
+
+// tokenPrices 在同一区块按报价路由查询多个代币的美元单价，查不到的代币不出现在结果里
+func tokenPrices(cfg Config, client *core.EvmClient, block *big.Int, tokens []common.Address) map[common.Address]*big.Float {
+	prices := make(map[common.Address]*big.Float)
+	oracle := newOracle(cfg, client, block)
+	if oracle == nil {
+		return prices
+	}
+	for _, token := range tokens {
+		quote, err := oracle.Price(token)
+		if err != nil {
+			logger.Debug("no usd price for token", "token", token, "err", err)
+			continue
+		}
+		prices[token] = quote.USD
+	}
+	return prices
+}
+
+// newOracle 创建固定在 block 上的报价器，失败时打印警告并返回 nil
+func newOracle(cfg Config, client *core.EvmClient, block *big.Int) *pricing.Oracle {
+	header, err := client.Client.HeaderByNumber(context.Background(), block)
+	if err != nil {
+		logger.Warn("skipping usd valuation: failed to get block time", "err", err)
+		return nil
+	}
+	mc, err := newMultiChecker(cfg, client, block)
+	if err != nil {
+		logger.Warn("skipping usd valuation", "err", err)
+		return nil
+	}
+	oracle, err := pricing.NewOracle(mc, time.Unix(int64(header.Time), 0), cfg.Prices)
+	if err != nil {
+		logger.Warn("skipping usd valuation: invalid price route", "err", err)
+		return nil
+	}
+	return oracle
+}
+
+// priceToken 报价路由里用来查找当前代币的地址，原生币用零地址表示
+func priceToken(cfg Config) common.Address {
+	tokenType, err := ParseTokenType(cfg.TokenType)
+	if err == nil && tokenType == multicall.TokenTypeNative {
+		return common.Address{}
+	}
+	return common.HexToAddress(cfg.TokenAddress)
+}
+
+// usdValue 计算 balance * price
+func usdValue(balance *big.Float, quote *pricing.Quote) *big.Float {
+	if balance == nil || quote == nil {
+		return nil
+	}
+	return new(big.Float).Mul(balance, quote.USD)
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"chain-lens/core"
+	"chain-lens/i18n"
+	"chain-lens/metrics"
+	"chain-lens/modules/multicall"
+	"chain-lens/notify"
+	"chain-lens/scanner"
+	"chain-lens/watch"
+	"context"
+	"flag"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// WatchConfig watch 子命令的配置，命令行参数优先
+type WatchConfig struct {
+	WsURL       string   `json:"ws_url,omitempty"`       // 可选：订阅 newHeads 用的 WebSocket 地址，为空时用 rpc_url
+	Interval    string   `json:"interval,omitempty"`     // 轮询间隔 (节点不支持订阅时)，如 "12s"
+	Thresholds  []string `json:"thresholds,omitempty"`   // 余额阈值 (代币单位)，穿过时额外发出 threshold 事件
+	Webhook     string   `json:"webhook,omitempty"`      // 可选：事件 POST 到这个 URL (不签名；需要签名时用 notify.webhooks)
+	Command     string   `json:"command,omitempty"`      // 可选：每个事件执行的 shell 命令
+	Transfers   bool     `json:"transfers,omitempty"`    // 订阅 Transfer 事件增量更新余额，代替每个区块重新查询
+	MetricsAddr string   `json:"metrics_addr,omitempty"` // 可选：在这个地址提供 /metrics
+}
+
+// runWatch watch 子命令：每个新区块重新查询钱包余额，余额变化或穿过阈值时发出事件。
+// 事件以 JSON Lines 写到 stdout，运行日志写到 stderr。
+//
+//	chain-lens watch -file wallets.txt -threshold 1000,10 -webhook https://example.com/hook
+//	chain-lens watch -exec 'jq -r .owner >> changed.txt' -json=false
+//	chain-lens watch -transfers -ws wss://...
+func runWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "配置文件路径")
+	filePath := fs.String("file", "wallets.txt", "包含钱包地址的文件路径 (每行一个)")
+	wsURL := fs.String("ws", "", "订阅 newHeads 用的 WebSocket 地址 (默认取配置 watch.ws_url，再为空时用 rpc_url)")
+	interval := fs.String("interval", "", "节点不支持订阅时的轮询间隔 (默认取配置 watch.interval)")
+	thresholds := fs.String("threshold", "", "余额阈值，逗号分隔，代币单位 (默认取配置 watch.thresholds)")
+	webhook := fs.String("webhook", "", "事件 POST 到这个 URL (默认取配置 watch.webhook)")
+	command := fs.String("exec", "", "每个事件执行的 shell 命令，事件 JSON 从 stdin 传入 (默认取配置 watch.command)")
+	jsonOut := fs.Bool("json", true, "事件以 JSON Lines 写到 stdout")
+	transfers := fs.Bool("transfers", false, "订阅 Transfer 事件增量更新余额 (需要 WebSocket，仅 erc20 / erc721)")
+	metricsAddr := fs.String("metrics-addr", "", "在这个地址提供 Prometheus /metrics (默认取配置 watch.metrics_addr)")
+	cli := addCommonFlags(fs)
+	fs.Parse(args)
+	cli.setup()
+
+	// 命令行没给的参数用配置文件里的值
+	cfg := loadConfig(*configPath)
+	wc := cfg.Watch
+	*transfers = *transfers || wc.Transfers
+	for _, p := range []struct {
+		flag *string
+		cfg  string
+	}{
+		{wsURL, wc.WsURL}, {interval, wc.Interval}, {thresholds, strings.Join(wc.Thresholds, ",")},
+		{webhook, wc.Webhook}, {command, wc.Command}, {metricsAddr, wc.MetricsAddr},
+	} {
+		if *p.flag == "" {
+			*p.flag = p.cfg
+		}
+	}
+
+	pollInterval := watch.DefaultPollInterval
+	if *interval != "" {
+		d, err := time.ParseDuration(*interval)
+		if err != nil {
+			fatal(i18n.Errorf("err.watch_interval", *interval))
+		}
+		pollInterval = d
+	}
+	var levels []string
+	for _, s := range strings.Split(*thresholds, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			levels = append(levels, s)
+		}
+	}
+	parsed, err := watch.ParseThresholds(levels)
+	if err != nil {
+		fatal(err)
+	}
+
+	var sinks []watch.Sink
+	if *jsonOut {
+		sinks = append(sinks, watch.NewJSONSink(os.Stdout))
+	}
+	// -webhook 和配置里 notify.webhooks 共用一个 Notifier (签名、重试、死信)
+	var nc notify.Config
+	if cfg.Notify != nil {
+		nc = *cfg.Notify
+	}
+	if *webhook != "" {
+		nc.Webhooks = append(slices.Clip(nc.Webhooks), notify.Webhook{URL: *webhook})
+	}
+	if len(nc.Webhooks) > 0 {
+		notifier, err := notify.New(nc)
+		if err != nil {
+			fatal(i18n.Errorf("err.notify_config", err))
+		}
+		sinks = append(sinks, watch.NewWebhookSink(notifier))
+	}
+	if *command != "" {
+		sinks = append(sinks, &watch.CommandSink{Command: *command})
+	}
+	if len(sinks) == 0 {
+		fatal(i18n.Errorf("err.watch_no_sinks"))
+	}
+
+	addresses, err := loadAddresses(*filePath)
+	if err != nil {
+		fatal(i18n.Errorf("err.read_file", err))
+	}
+	tokenType, err := ParseTokenType(cfg.TokenType)
+	if err != nil {
+		fatal(err)
+	}
+	client, err := connect(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	defer client.Close()
+	// 每个区块的查询和默认命令一样走 Scanner：按批 Multicall，失败的钱包逐个补查
+	balanceScanner, err := newScanner(cfg, core.NewPoolOf(client), nil)
+	if err != nil {
+		fatal(err)
+	}
+
+	if *metricsAddr != "" {
+		serveMetrics(*metricsAddr)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// 事件异步投递，慢的 webhook 不会卡住区块处理；退出前等排队的事件投递完
+	dispatcher := watch.NewDispatcher(sinks, watch.DefaultQueueSize, logger)
+	defer dispatcher.Close()
+	tracker := watch.NewTracker(parsed)
+	token := common.HexToAddress(cfg.TokenAddress)
+	if *transfers {
+		url := *wsURL
+		if url == "" {
+			url = cfg.RpcURL
+		}
+		watchTransfers(ctx, client, balanceScanner, tokenType, token, url, addresses, tracker, dispatcher)
+		return
+	}
+
+	// 区块通知可以走单独的 WebSocket 连接，余额查询仍然用 rpc_url
+	headClient := client.Client
+	if *wsURL != "" {
+		ws, err := ethclient.Dial(*wsURL)
+		if err != nil {
+			fatal(i18n.Errorf("err.ws_connect", err))
+		}
+		defer ws.Close()
+		headClient = ws
+	}
+	mode := "polling every " + pollInterval.String()
+	if headClient.Client().SupportsSubscriptions() {
+		mode = "newHeads subscription"
+	}
+
+	logger.Info("watching balances", "wallets", len(addresses), "chain", client.Chain.Name, "chain_id", client.Chain.ChainID, "heads", mode, "sinks", len(sinks))
+
+	for blockNumber := range watch.Heads(ctx, headClient, pollInterval) {
+		results, err := balanceScanner.AtBlock(new(big.Int).SetUint64(blockNumber)).Scan(ctx, addresses)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			logger.Warn("balance check failed", "block", blockNumber, "err", err)
+			continue
+		}
+		balances := results[0].Balances
+		events := tracker.Update(client.ChainID.Int64(), blockNumber, balances)
+		trackBalances(client.ChainID.Int64(), token, balances)
+		dispatcher.Dispatch(events...)
+		logger.Info("block checked", "block", blockNumber, "wallets", len(balances), "failed", countFailed(balances), "events", len(events))
+	}
+	logger.Info("watch stopped")
+}
+
+// watchTransfers 在当前区块查询一次初始余额，之后只根据订阅到的 Transfer 增量更新，
+// 断线重连和补拉由 watch.TransferStream 处理
+func watchTransfers(ctx context.Context, client *core.EvmClient, s *scanner.Scanner, tokenType multicall.TokenType, token common.Address, url string, addresses []common.Address, tracker *watch.Tracker, dispatcher *watch.Dispatcher) {
+	stream, err := watch.NewTransferStream(url, tokenType, token)
+	if err != nil {
+		fatal(err)
+	}
+	head, err := client.Client.BlockNumber(ctx)
+	if err != nil {
+		fatal(i18n.Errorf("err.block_number", err))
+	}
+	caller := s.AtBlock(new(big.Int).SetUint64(head)).Checker(client)
+	info, err := multicall.DetectToken(caller, token)
+	if err != nil {
+		fatal(i18n.Errorf("err.detect_token", err))
+	}
+	view := watch.NewBalanceView(token, info.Symbol, info.Decimals)
+	if err := view.Seed(caller, addresses, head); err != nil {
+		fatal(i18n.Errorf("err.seed_balances", err))
+	}
+	tracker.Update(client.ChainID.Int64(), head, view.Balances())
+	trackBalances(client.ChainID.Int64(), token, view.Balances())
+	logger.Info("streaming transfers", "symbol", info.Symbol, "wallets", len(addresses), "chain", client.Chain.Name, "chain_id", client.Chain.ChainID, "from_block", head)
+
+	err = stream.Run(ctx, head+1, func(t watch.Transfer) {
+		changed := view.Apply(t)
+		if len(changed) == 0 {
+			return
+		}
+		balances := view.Balances(changed...)
+		events := tracker.Update(client.ChainID.Int64(), t.Block, balances)
+		trackBalances(client.ChainID.Int64(), token, balances)
+		dispatcher.Dispatch(events...)
+		logger.Info("transfer applied", "block", t.Block, "from", t.From, "to", t.To, "events", len(events))
+	})
+	if err != nil {
+		fatal(err)
+	}
+	logger.Info("watch stopped")
+}
+
+// trackBalances 把最新余额写到 wallet_balance 指标，token 标签和默认命令一样用配置代币地址的校验和格式
+func trackBalances(chainID int64, token common.Address, balances []core.TokenBalance) {
+	for _, tb := range balances {
+		if tb.Success {
+			metrics.SetBalance(chainID, token.Hex(), tb.Symbol, tb.Owner.Hex(), tb.Balance)
+		}
+	}
+}
+
+// serveMetrics 在后台提供 /metrics
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		fatal(server.ListenAndServe())
+	}()
+	logger.Info("metrics serving", "addr", addr, "path", "/metrics")
+}
+
+func countFailed(balances []core.TokenBalance) int {
+	failed := 0
+	for _, tb := range balances {
+		if !tb.Success {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,71 @@
+package watch
+
+import (
+	"chain-lens/core"
+	"fmt"
+	"sync"
+)
+
+// DefaultQueueSize 每个出口排队等待投递的事件数上限
+const DefaultQueueSize = 1024
+
+// Dispatcher 异步投递事件：每个出口一个有界队列和一个 worker。
+// webhook 重试可能持续一分钟左右，放在区块处理的 goroutine 里会卡住后续区块，也会拖慢其他出口；
+// 这里同一出口的事件按顺序投递，出口之间互不影响。队列满时丢弃新事件并记录警告。
+type Dispatcher struct {
+	Logger core.Logger // 诊断日志，nil 表示 core.DefaultLogger
+	sinks  []Sink
+	queues []chan Event
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher 为每个出口启动一个 worker，queueSize <= 0 时使用 DefaultQueueSize
+func NewDispatcher(sinks []Sink, queueSize int, logger core.Logger) *Dispatcher {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	d := &Dispatcher{Logger: logger, sinks: sinks, queues: make([]chan Event, len(sinks))}
+	for i, sink := range sinks {
+		q := make(chan Event, queueSize)
+		d.queues[i] = q
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for ev := range q {
+				if err := sink.Send(ev); err != nil {
+					d.log().Warn("event delivery failed", "sink", sinkName(sink), "owner", ev.Owner, "block", ev.Block, "err", err)
+				}
+			}
+		}()
+	}
+	return d
+}
+
+// Dispatch 把事件放进每个出口的队列，不等待投递完成
+func (d *Dispatcher) Dispatch(events ...Event) {
+	for _, ev := range events {
+		for i, q := range d.queues {
+			select {
+			case q <- ev:
+			default:
+				d.log().Warn("event queue full, dropping event", "sink", sinkName(d.sinks[i]), "owner", ev.Owner, "block", ev.Block)
+			}
+		}
+	}
+}
+
+// Close 停止接收事件，等待已排队的事件投递完 (webhook 的重试有上限，所以会在有限时间内返回)
+func (d *Dispatcher) Close() {
+	for _, q := range d.queues {
+		close(q)
+	}
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) log() core.Logger {
+	return core.LoggerOrDefault(d.Logger)
+}
+
+func sinkName(s Sink) string {
+	return fmt.Sprintf("%T", s)
+}
@@ -0,0 +1,81 @@
+package watch
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultPollInterval 轮询模式下查询区块高度的间隔
+const DefaultPollInterval = 12 * time.Second
+
+// Heads 推送新的区块高度，ctx 结束时关闭通道。
+// 连接支持订阅 (ws / ipc) 时使用 newHeads，订阅失败或中断后改为按 interval 轮询。
+// 消费跟不上时只保留最新的高度，中间的区块直接跳过。
+func Heads(ctx context.Context, client *ethclient.Client, interval time.Duration) <-chan uint64 {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	out := make(chan uint64, 1)
+	go func() {
+		defer close(out)
+		var last uint64
+		emit := func(n uint64) {
+			if n <= last {
+				return
+			}
+			last = n
+			select {
+			case <-out: // 丢掉还没被取走的旧高度
+			default:
+			}
+			out <- n
+		}
+		if client.Client().SupportsSubscriptions() {
+			subscribeHeads(ctx, client, emit)
+		}
+		pollHeads(ctx, client, interval, emit)
+	}()
+	return out
+}
+
+// subscribeHeads 订阅 newHeads 直到 ctx 结束或订阅出错
+func subscribeHeads(ctx context.Context, client *ethclient.Client, emit func(uint64)) {
+	headers := make(chan *types.Header, 16)
+	sub, err := client.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		return
+	}
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case h := <-headers:
+			emit(h.Number.Uint64())
+		case <-sub.Err():
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollHeads 定时查询区块高度，单次失败等下一轮再试
+func pollHeads(ctx context.Context, client *ethclient.Client, interval time.Duration, emit func(uint64)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		reqCtx, cancel := context.WithTimeout(ctx, interval)
+		n, err := client.BlockNumber(reqCtx)
+		cancel()
+		if err == nil {
+			emit(n)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
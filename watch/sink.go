@@ -0,0 +1,88 @@
+package watch
+
+import (
+	"bytes"
+	"chain-lens/notify"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SinkTimeout 单个事件执行命令的超时时间
+const SinkTimeout = 30 * time.Second
+
+// Sink 告警出口
+type Sink interface {
+	Send(ev Event) error
+}
+
+// JSONSink 每个事件输出一行 JSON (JSON Lines)，一般写到 stdout 供其他程序消费
+type JSONSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{enc: json.NewEncoder(w)}
+}
+
+func (s *JSONSink) Send(ev Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(ev)
+}
+
+// WebhookSink 通过 notify.Notifier 投递事件：HMAC 签名、失败退避重试、最终失败写入死信目录
+type WebhookSink struct {
+	Notifier *notify.Notifier
+}
+
+func NewWebhookSink(n *notify.Notifier) *WebhookSink {
+	return &WebhookSink{Notifier: n}
+}
+
+func (s *WebhookSink) Send(ev Event) error {
+	return s.Notifier.Send(notify.EventBalanceChange, ev)
+}
+
+// CommandSink 每个事件执行一次 shell 命令。
+// 事件 JSON 从 stdin 传入，常用字段同时放在 CHAIN_LENS_* 环境变量里，方便直接在命令里引用。
+type CommandSink struct {
+	Command string
+}
+
+func (s *CommandSink) Send(ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), SinkTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.Command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = os.Stderr // 命令输出不能混进 stdout 的事件流
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"CHAIN_LENS_EVENT="+string(ev.Type),
+		"CHAIN_LENS_CHAIN_ID="+strconv.FormatInt(ev.ChainID, 10),
+		"CHAIN_LENS_BLOCK="+strconv.FormatUint(ev.Block, 10),
+		"CHAIN_LENS_OWNER="+ev.Owner.Hex(),
+		"CHAIN_LENS_TOKEN="+ev.TokenAddress.Hex(),
+		"CHAIN_LENS_SYMBOL="+ev.Symbol,
+		"CHAIN_LENS_FROM="+ev.From,
+		"CHAIN_LENS_TO="+ev.To,
+		"CHAIN_LENS_DELTA="+ev.Delta,
+		"CHAIN_LENS_THRESHOLD="+ev.Threshold,
+		"CHAIN_LENS_DIRECTION="+ev.Direction,
+	)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("command %q: %w", s.Command, err)
+	}
+	return nil
+}
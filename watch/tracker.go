@@ -0,0 +1,107 @@
+package watch
+
+import (
+	"chain-lens/core"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EventType 事件类型
+type EventType string
+
+const (
+	EventChange    EventType = "change"    // 余额发生变化
+	EventThreshold EventType = "threshold" // 余额穿过阈值
+)
+
+// Event 一次余额变化或阈值穿越，所有告警出口 (stdout / webhook / 命令) 收到的都是这个结构
+type Event struct {
+	Type         EventType      `json:"type"`
+	ChainID      int64          `json:"chain_id"`
+	Block        uint64         `json:"block"`
+	Owner        common.Address `json:"owner"`
+	TokenAddress common.Address `json:"token_address"`
+	Symbol       string         `json:"symbol"`
+	From         string         `json:"from"` // 十进制字符串，避免 JSON 浮点精度丢失
+	To           string         `json:"to"`
+	Delta        string         `json:"delta"`
+	Threshold    string         `json:"threshold,omitempty"` // 阈值事件：被穿过的阈值
+	Direction    string         `json:"direction,omitempty"` // 阈值事件：up 向上穿过 / down 向下穿过
+	Timestamp    time.Time      `json:"timestamp"`
+}
+
+// Tracker 记录每个钱包上一次的余额，和新结果对比生成事件
+type Tracker struct {
+	Thresholds []*big.Float
+	last       map[common.Address]*big.Float
+}
+
+func NewTracker(thresholds []*big.Float) *Tracker {
+	return &Tracker{Thresholds: thresholds, last: make(map[common.Address]*big.Float)}
+}
+
+// ParseThresholds 解析阈值列表 (按代币单位的十进制数)
+func ParseThresholds(values []string) ([]*big.Float, error) {
+	thresholds := make([]*big.Float, 0, len(values))
+	for _, v := range values {
+		f, ok := new(big.Float).SetString(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid threshold: %q", v)
+		}
+		thresholds = append(thresholds, f)
+	}
+	return thresholds, nil
+}
+
+// Update 用新区块上的查询结果更新状态并返回事件。
+// 第一次出现的钱包只记录基线，查询失败的钱包保留旧值，都不产生事件。
+func (t *Tracker) Update(chainID int64, block uint64, balances []core.TokenBalance) []Event {
+	now := time.Now().UTC()
+	var events []Event
+	for _, tb := range balances {
+		if !tb.Success || tb.Balance == nil {
+			continue
+		}
+		prev, seen := t.last[tb.Owner]
+		t.last[tb.Owner] = tb.Balance
+		if !seen || prev.Cmp(tb.Balance) == 0 {
+			continue
+		}
+
+		base := Event{
+			Type:         EventChange,
+			ChainID:      chainID,
+			Block:        block,
+			Owner:        tb.Owner,
+			TokenAddress: tb.TokenAddress,
+			Symbol:       tb.Symbol,
+			From:         prev.Text('f', -1),
+			To:           tb.Balance.Text('f', -1),
+			Delta:        new(big.Float).Sub(tb.Balance, prev).Text('f', -1),
+			Timestamp:    now,
+		}
+		events = append(events, base)
+
+		for _, th := range t.Thresholds {
+			// 向上：之前低于阈值，现在不低于；向下：之前不低于阈值，现在低于
+			var direction string
+			switch {
+			case prev.Cmp(th) < 0 && tb.Balance.Cmp(th) >= 0:
+				direction = "up"
+			case prev.Cmp(th) >= 0 && tb.Balance.Cmp(th) < 0:
+				direction = "down"
+			default:
+				continue
+			}
+			ev := base
+			ev.Type = EventThreshold
+			ev.Threshold = th.Text('f', -1)
+			ev.Direction = direction
+			events = append(events, ev)
+		}
+	}
+	return events
+}
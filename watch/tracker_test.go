@@ -0,0 +1,117 @@
+package watch
+
+import (
+	"chain-lens/core"
+	"chain-lens/notify"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTrackerUpdate(t *testing.T) {
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	bal := func(owner common.Address, v float64, ok bool) core.TokenBalance {
+		return core.TokenBalance{Owner: owner, Balance: big.NewFloat(v), Symbol: "TKN", Success: ok}
+	}
+	thresholds, err := ParseThresholds([]string{"10", "100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTracker(thresholds)
+
+	if events := tr.Update(1, 100, []core.TokenBalance{bal(alice, 5, true), bal(bob, 50, true)}); len(events) != 0 {
+		t.Fatalf("first update only records a baseline, got %+v", events)
+	}
+	// bob 查询失败，保留旧值不产生事件
+	events := tr.Update(1, 101, []core.TokenBalance{bal(alice, 150, true), bal(bob, 0, false)})
+	if len(events) != 3 {
+		t.Fatalf("expected change + 2 threshold events, got %+v", events)
+	}
+	if ev := events[0]; ev.Type != EventChange || ev.From != "5" || ev.To != "150" || ev.Delta != "145" || ev.Block != 101 {
+		t.Fatalf("unexpected change event: %+v", ev)
+	}
+	if events[1].Threshold != "10" || events[2].Threshold != "100" || events[2].Direction != "up" {
+		t.Fatalf("unexpected threshold events: %+v", events[1:])
+	}
+
+	events = tr.Update(1, 102, []core.TokenBalance{bal(alice, 150, true), bal(bob, 5, true)})
+	if len(events) != 2 || events[0].Owner != bob || events[1].Direction != "down" || events[1].Threshold != "10" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got struct {
+		Event string `json:"event"`
+		Data  Event  `json:"data"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	ev := Event{Type: EventThreshold, Block: 7, Owner: common.HexToAddress("0xa1"), To: "1.5", Direction: "up"}
+	n, err := notify.New(notify.Config{Webhooks: []notify.Webhook{{URL: srv.URL}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := NewWebhookSink(n)
+	if err := sink.Send(ev); err != nil {
+		t.Fatal(err)
+	}
+	if got.Event != notify.EventBalanceChange || got.Data.Type != EventThreshold || got.Data.Block != 7 || got.Data.To != "1.5" {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+}
+
+// blockingSink 在 release 关闭前阻塞，模拟正在重试的 webhook
+type blockingSink struct {
+	release chan struct{}
+	mu      sync.Mutex
+	got     []Event
+}
+
+func (s *blockingSink) Send(ev Event) error {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.got = append(s.got, ev)
+	return nil
+}
+
+func TestDispatcherDoesNotBlock(t *testing.T) {
+	slow := &blockingSink{release: make(chan struct{})}
+	fast := &blockingSink{release: make(chan struct{})}
+	close(fast.release)
+	d := NewDispatcher([]Sink{slow, fast}, 2, core.NopLogger())
+
+	events := []Event{{Block: 1}, {Block: 2}, {Block: 3}}
+	done := make(chan struct{})
+	go func() {
+		d.Dispatch(events...) // 慢出口的队列满了只丢弃，不能阻塞调用方
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Dispatch blocked on a slow sink")
+	}
+
+	close(slow.release)
+	d.Close()
+	// 每个出口至少收到队列容量内的事件，超出的可能被丢弃；同一出口内顺序保持不变
+	for name, s := range map[string]*blockingSink{"slow": slow, "fast": fast} {
+		if len(s.got) < 2 || s.got[0].Block != 1 || s.got[1].Block != 2 {
+			t.Errorf("%s sink got %+v", name, s.got)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package watch
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/erc20"
+	"chain-lens/modules/erc721"
+	"chain-lens/modules/multicall"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+const (
+	ReconnectMin = 1 * time.Second  // 断线后第一次重连的等待时间
+	ReconnectMax = 30 * time.Second // 重连等待时间上限 (指数退避)
+)
+
+// Transfer ERC20 / ERC721 的一条 Transfer 事件。ERC721 的 Value 固定为 1，TokenID 为转移的 NFT。
+type Transfer struct {
+	From     common.Address
+	To       common.Address
+	Value    *big.Int
+	TokenID  *big.Int
+	Block    uint64
+	TxHash   common.Hash
+	LogIndex uint
+	Removed  bool // 链重组时撤销的日志
+}
+
+// TransferStream 通过 WebSocket 订阅代币的 Transfer 事件。
+// 断线后自动重连，并用 FilterTransfer 补拉断线期间的区块，保证不漏事件 (可能重复，由 BalanceView 去重)。
+//
+// 订阅不带地址过滤：同时匹配 from 或 to 需要两个订阅，而且节点通常拒绝上千个地址的 topic 过滤，
+// 所以收到全部 Transfer 后再由调用方按钱包集合筛选。
+type TransferStream struct {
+	URL    string // WebSocket (或 IPC) 地址
+	Token  common.Address
+	Type   multicall.TokenType
+	Chunk  uint64      // 补拉时每次 eth_getLogs 的区块数，0 表示 core.DefaultLogChunk
+	Logger core.Logger // 诊断日志，nil 表示 core.DefaultLogger
+}
+
+func NewTransferStream(url string, tType multicall.TokenType, token common.Address) (*TransferStream, error) {
+	if tType == multicall.TokenTypeNative {
+		return nil, errors.New("native token has no Transfer events, streaming needs erc20 or erc721")
+	}
+	return &TransferStream{URL: url, Token: token, Type: tType}, nil
+}
+
+// Run 从 from 区块开始推送 Transfer，直到 ctx 结束。
+// 每次 (重新) 连接先建立订阅再补拉 [上次处理到的区块, 当前区块]，补拉期间到达的实时事件在通道里排队。
+func (s *TransferStream) Run(ctx context.Context, from uint64, handle func(Transfer)) error {
+	next := from // 下一次补拉的起点；重连时从已处理的最高区块重新拉，重复的事件由调用方去重
+	backoff := ReconnectMin
+	for {
+		start := time.Now()
+		err := s.session(ctx, &next, handle)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if time.Since(start) > ReconnectMax {
+			backoff = ReconnectMin // 连接稳定运行过一段时间，重新从最短等待开始
+		}
+		core.LoggerOrDefault(s.Logger).Warn("transfer subscription lost, reconnecting", "err", err, "retry_in", backoff, "backfill_from", next)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		backoff = min(backoff*2, ReconnectMax)
+	}
+}
+
+// session 一次连接的生命周期：订阅 → 补拉 → 处理实时事件，连接或订阅出错时返回
+func (s *TransferStream) session(ctx context.Context, next *uint64, handle func(Transfer)) error {
+	client, err := ethclient.DialContext(ctx, s.URL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	live := make(chan Transfer, 1024)
+	sub, err := s.subscribe(client, live)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if *next <= head {
+		err := s.backfill(client, *next, head, func(t Transfer) {
+			handle(t)
+			*next = max(*next, t.Block)
+		})
+		if err != nil {
+			return fmt.Errorf("backfill %d-%d: %w", *next, head, err)
+		}
+		*next = max(*next, head)
+	}
+
+	for {
+		select {
+		case t := <-live:
+			handle(t)
+			*next = max(*next, t.Block)
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// subscribe 用绑定生成的 WatchTransfer 订阅事件，并转换成统一的 Transfer
+func (s *TransferStream) subscribe(client *ethclient.Client, out chan<- Transfer) (event.Subscription, error) {
+	switch s.Type {
+	case multicall.TokenTypeERC20:
+		filterer, err := erc20.NewTokenFilterer(s.Token, client)
+		if err != nil {
+			return nil, err
+		}
+		ch := make(chan *erc20.TokenTransfer, 256)
+		inner, err := filterer.WatchTransfer(&bind.WatchOpts{}, ch, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return forward(inner, ch, out, func(ev *erc20.TokenTransfer) Transfer {
+			return newTransfer(ev.From, ev.To, ev.Value, nil, ev.Raw)
+		}), nil
+	case multicall.TokenTypeERC721:
+		filterer, err := erc721.NewErc721Filterer(s.Token, client)
+		if err != nil {
+			return nil, err
+		}
+		ch := make(chan *erc721.Erc721Transfer, 256)
+		inner, err := filterer.WatchTransfer(&bind.WatchOpts{}, ch, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return forward(inner, ch, out, func(ev *erc721.Erc721Transfer) Transfer {
+			return newTransfer(ev.From, ev.To, big.NewInt(1), ev.TokenId, ev.Raw)
+		}), nil
+	default:
+		return nil, errors.New("unsupported token type")
+	}
+}
+
+// forward 把绑定的事件通道转发到 out，返回的订阅在内部订阅出错或取消时结束
+func forward[E any](inner event.Subscription, in <-chan E, out chan<- Transfer, convert func(E) Transfer) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer inner.Unsubscribe()
+		for {
+			select {
+			case ev := <-in:
+				select {
+				case out <- convert(ev):
+				case <-quit:
+					return nil
+				}
+			case err := <-inner.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	})
+}
+
+// backfill 用 FilterTransfer 拉取 [from, to] 区块内的 Transfer，区块范围按节点限制自适应切分
+func (s *TransferStream) backfill(client *ethclient.Client, from, to uint64, handle func(Transfer)) error {
+	var fetch func(start, end uint64) error
+	switch s.Type {
+	case multicall.TokenTypeERC20:
+		filterer, err := erc20.NewTokenFilterer(s.Token, client)
+		if err != nil {
+			return err
+		}
+		fetch = func(start, end uint64) error {
+			it, err := filterer.FilterTransfer(&bind.FilterOpts{Start: start, End: &end}, nil, nil)
+			if err != nil {
+				return err
+			}
+			defer it.Close()
+			for it.Next() {
+				handle(newTransfer(it.Event.From, it.Event.To, it.Event.Value, nil, it.Event.Raw))
+			}
+			return it.Error()
+		}
+	case multicall.TokenTypeERC721:
+		filterer, err := erc721.NewErc721Filterer(s.Token, client)
+		if err != nil {
+			return err
+		}
+		fetch = func(start, end uint64) error {
+			it, err := filterer.FilterTransfer(&bind.FilterOpts{Start: start, End: &end}, nil, nil, nil)
+			if err != nil {
+				return err
+			}
+			defer it.Close()
+			for it.Next() {
+				handle(newTransfer(it.Event.From, it.Event.To, big.NewInt(1), it.Event.TokenId, it.Event.Raw))
+			}
+			return it.Error()
+		}
+	default:
+		return errors.New("unsupported token type")
+	}
+	return core.ScanBlockRange(from, to, s.Chunk, fetch)
+}
+
+func newTransfer(from, to common.Address, value, tokenID *big.Int, raw types.Log) Transfer {
+	return Transfer{
+		From:     from,
+		To:       to,
+		Value:    value,
+		TokenID:  tokenID,
+		Block:    raw.BlockNumber,
+		TxHash:   raw.TxHash,
+		LogIndex: raw.Index,
+		Removed:  raw.Removed,
+	}
+}
@@ -0,0 +1,193 @@
+package watch
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/erc20"
+	"chain-lens/modules/multicall"
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type filterArg struct {
+	Address   []common.Address `json:"address"`
+	FromBlock rpc.BlockNumber  `json:"fromBlock"`
+	ToBlock   rpc.BlockNumber  `json:"toBlock"` // 订阅时为 "latest"
+	Topics    [][]common.Hash  `json:"topics"`
+}
+
+// liveSub 一个 logs 订阅，测试通过它推送实时日志
+type liveSub struct {
+	notifier *rpc.Notifier
+	id       rpc.ID
+}
+
+// fakeChain 假节点：eth_blockNumber、eth_getLogs 和 eth_subscribe("logs")，日志在每次连接之间保留
+type fakeChain struct {
+	mu   sync.Mutex
+	head uint64
+	logs []types.Log
+	subs chan liveSub // 每个新建立的订阅
+}
+
+func (f *fakeChain) BlockNumber() hexutil.Uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return hexutil.Uint64(f.head)
+}
+
+func (f *fakeChain) GetLogs(arg filterArg) ([]types.Log, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []types.Log
+	for _, l := range f.logs {
+		if l.BlockNumber >= uint64(arg.FromBlock) && l.BlockNumber <= uint64(arg.ToBlock) && slices.Contains(arg.Address, l.Address) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeChain) Logs(ctx context.Context, arg filterArg) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	f.subs <- liveSub{notifier: notifier, id: sub.ID}
+	return sub, nil
+}
+
+// mine 把日志加到链上并推进区块高度，sub 不为空时同时作为实时日志推送
+func (f *fakeChain) mine(l types.Log, sub *liveSub) {
+	f.mu.Lock()
+	f.logs = append(f.logs, l)
+	f.head = l.BlockNumber
+	f.mu.Unlock()
+	if sub != nil {
+		sub.notifier.Notify(sub.id, l)
+	}
+}
+
+func TestTransferStreamReconnect(t *testing.T) {
+	token := common.HexToAddress("0x70")
+	alice := common.HexToAddress("0xa1")
+	other := common.HexToAddress("0xcc")
+	parsed, _ := erc20.TokenMetaData.GetAbi()
+	transfer := func(block uint64) types.Log {
+		return types.Log{
+			Address:     token,
+			Topics:      []common.Hash{parsed.Events["Transfer"].ID, common.BytesToHash(alice.Bytes()), common.BytesToHash(other.Bytes())},
+			Data:        common.BigToHash(big.NewInt(1)).Bytes(),
+			BlockNumber: block,
+			TxHash:      common.BigToHash(new(big.Int).SetUint64(block)),
+		}
+	}
+
+	chain := &fakeChain{head: 10, logs: []types.Log{transfer(5)}, subs: make(chan liveSub, 4)}
+	newNode := func() *rpc.Server {
+		node := rpc.NewServer()
+		if err := node.RegisterName("eth", chain); err != nil {
+			t.Fatal(err)
+		}
+		return node
+	}
+	// 每个连接交给当前的 rpc.Server；Stop 旧的 Server 会断开它上面的所有连接
+	var current atomic.Pointer[rpc.Server]
+	current.Store(newNode())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current.Load().WebsocketHandler(nil).ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { current.Load().Stop() })
+
+	stream, err := NewTransferStream("ws"+strings.TrimPrefix(srv.URL, "http"), multicall.TokenTypeERC20, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Logger = core.NopLogger()
+	view := NewBalanceView(token, "TKN", 0)
+	if err := view.Seed(fakeBalances{alice: 100}, []common.Address{alice}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(chan Transfer, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- stream.Run(ctx, 1, func(tr Transfer) { got <- tr }) }()
+
+	var received []uint64
+	applied := 0
+	next := func() uint64 {
+		t.Helper()
+		select {
+		case tr := <-got:
+			received = append(received, tr.Block)
+			if view.Apply(tr) != nil {
+				applied++
+			}
+			return tr.Block
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for a transfer, received %v", received)
+			return 0
+		}
+	}
+	subscribed := func() liveSub {
+		t.Helper()
+		select {
+		case sub := <-chain.subs:
+			return sub
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a subscription")
+			return liveSub{}
+		}
+	}
+
+	// 第一次连接：补拉 [1, 10]，再收到一条实时日志
+	sub := subscribed()
+	next()
+	chain.mine(transfer(11), &sub)
+	next()
+
+	// 断线期间出了两个区块，之后节点恢复
+	chain.mine(transfer(12), nil)
+	chain.mine(transfer(13), nil)
+	dropped := time.Now()
+	current.Swap(newNode()).Stop()
+
+	// 重连后从已处理的最高区块补拉 [11, 13]：11 重复，12、13 各一次
+	sub = subscribed()
+	if waited := time.Since(dropped); waited < ReconnectMin {
+		t.Errorf("reconnected after %v, expected a backoff of %v", waited, ReconnectMin)
+	}
+	for next() != 13 {
+	}
+	chain.mine(transfer(14), &sub)
+	next()
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint64{5, 11, 11, 12, 13, 14}; !slices.Equal(received, want) {
+		t.Errorf("received blocks %v, want %v", received, want)
+	}
+	if applied != 5 {
+		t.Errorf("applied %d transfers, want 5", applied)
+	}
+	if bal := view.Balances(alice)[0].Balance.Text('f', -1); bal != "95" || view.Block() != 14 {
+		t.Errorf("alice balance %s at block %d, want 95 at 14", bal, view.Block())
+	}
+}
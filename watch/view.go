@@ -0,0 +1,161 @@
+package watch
+
+import (
+	"chain-lens/core"
+	"chain-lens/modules/erc20"
+	"chain-lens/tools"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ReorgDepth 去重记录保留的区块数，超过这个深度的重组不再处理
+const ReorgDepth = 128
+
+// logID 唯一标识一条日志，用于去重 (补拉和实时订阅可能收到同一条 Transfer)
+type logID struct {
+	TxHash common.Hash
+	Index  uint
+}
+
+// BalanceView 一组钱包的内存余额视图，收到 Transfer 后增量更新，不再重新查询全部余额。
+// 余额按链上原始单位 (wei / NFT 数量) 保存，读取时再按 Decimals 换算。
+type BalanceView struct {
+	Token    common.Address
+	Symbol   string
+	Decimals uint8
+
+	mu       sync.RWMutex
+	balances map[common.Address]*big.Int
+	applied  map[logID]uint64 // 已应用的日志 → 所在区块
+	seeded   uint64           // 初始余额所在区块，不晚于它的 Transfer 已经包含在初始余额里
+	block    uint64           // 已处理到的最高区块
+}
+
+func NewBalanceView(token common.Address, symbol string, decimals uint8) *BalanceView {
+	return &BalanceView{
+		Token:    token,
+		Symbol:   symbol,
+		Decimals: decimals,
+		balances: make(map[common.Address]*big.Int),
+		applied:  make(map[logID]uint64),
+	}
+}
+
+// Seed 在 block 上批量查询 balanceOf 作为初始余额。ERC20 和 ERC721 的 balanceOf 签名相同。
+func (v *BalanceView) Seed(caller core.BatchCaller, owners []common.Address, block uint64) error {
+	parsed, err := erc20.TokenMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	calls := make([]core.Call, 0, len(owners))
+	for _, owner := range owners {
+		data, err := parsed.Pack("balanceOf", owner)
+		if err != nil {
+			return err
+		}
+		calls = append(calls, core.Call{Target: v.Token, Data: data})
+	}
+	results, err := core.AggregateInBatches(caller, calls, core.DefaultBatchSize)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for i, res := range results {
+		if !res.Success || len(res.ReturnData) < 32 {
+			return fmt.Errorf("balanceOf(%s) failed at block %d", owners[i].Hex(), block)
+		}
+		v.balances[owners[i]] = new(big.Int).SetBytes(res.ReturnData[:32])
+	}
+	v.seeded, v.block = block, block
+	return nil
+}
+
+// Apply 应用一条 Transfer，返回余额发生变化的被跟踪钱包。
+// 已处理过的日志直接忽略；Removed (链重组撤销) 的日志会回滚之前的变更。
+func (v *BalanceView) Apply(t Transfer) []common.Address {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if t.Block <= v.seeded {
+		return nil
+	}
+	id := logID{TxHash: t.TxHash, Index: t.LogIndex}
+	value := t.Value
+	if t.Removed {
+		if _, ok := v.applied[id]; !ok {
+			return nil
+		}
+		delete(v.applied, id)
+		value = new(big.Int).Neg(value)
+	} else {
+		if _, ok := v.applied[id]; ok {
+			return nil
+		}
+		v.applied[id] = t.Block
+		if t.Block > v.block {
+			v.block = t.Block
+			v.prune()
+		}
+	}
+
+	var changed []common.Address
+	if bal, ok := v.balances[t.From]; ok && t.From != t.To {
+		bal.Sub(bal, value)
+		changed = append(changed, t.From)
+	}
+	if bal, ok := v.balances[t.To]; ok && t.From != t.To {
+		bal.Add(bal, value)
+		changed = append(changed, t.To)
+	}
+	return changed
+}
+
+// prune 丢掉早于重组深度的去重记录，避免长时间运行时无限增长
+func (v *BalanceView) prune() {
+	if v.block <= ReorgDepth {
+		return
+	}
+	for id, block := range v.applied {
+		if block < v.block-ReorgDepth {
+			delete(v.applied, id)
+		}
+	}
+}
+
+// Block 已处理到的最高区块
+func (v *BalanceView) Block() uint64 {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.block
+}
+
+// Balances 返回指定钱包的当前余额 (为空时返回全部)，格式和 MultiChecker 的查询结果一致
+func (v *BalanceView) Balances(owners ...common.Address) []core.TokenBalance {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if len(owners) == 0 {
+		for owner := range v.balances {
+			owners = append(owners, owner)
+		}
+	}
+	balances := make([]core.TokenBalance, 0, len(owners))
+	for _, owner := range owners {
+		raw, ok := v.balances[owner]
+		if !ok {
+			continue
+		}
+		balances = append(balances, core.TokenBalance{
+			Symbol:       v.Symbol,
+			TokenAddress: v.Token,
+			Balance:      tools.WeiToEther(raw, v.Decimals),
+			Owner:        owner,
+			Success:      true,
+		})
+	}
+	return balances
+}
@@ -0,0 +1,50 @@
+package watch
+
+import (
+	"chain-lens/core"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeBalances 按 balanceOf 参数里的地址返回固定余额
+type fakeBalances map[common.Address]int64
+
+func (f fakeBalances) Aggregate(calls []core.Call) ([]core.CallResult, error) {
+	results := make([]core.CallResult, len(calls))
+	for i, c := range calls {
+		owner := common.BytesToAddress(c.Data[4:36])
+		results[i] = core.CallResult{Success: true, ReturnData: common.LeftPadBytes(big.NewInt(f[owner]).Bytes(), 32)}
+	}
+	return results, nil
+}
+
+func TestBalanceView(t *testing.T) {
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	other := common.HexToAddress("0xcc")
+	view := NewBalanceView(common.HexToAddress("0x70"), "TKN", 2)
+	if err := view.Seed(fakeBalances{alice: 1000, bob: 0}, []common.Address{alice, bob}, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := Transfer{From: alice, To: other, Value: big.NewInt(250), Block: 101, TxHash: common.HexToHash("0x01")}
+	if changed := view.Apply(tx); len(changed) != 1 || changed[0] != alice {
+		t.Fatalf("unexpected changed wallets: %v", changed)
+	}
+	if changed := view.Apply(tx); changed != nil {
+		t.Fatal("duplicate log must be ignored")
+	}
+	if changed := view.Apply(Transfer{From: bob, To: alice, Value: big.NewInt(5), Block: 100}); changed != nil {
+		t.Fatal("transfers up to the seed block are already in the initial balances")
+	}
+	view.Apply(Transfer{From: other, To: bob, Value: big.NewInt(40), Block: 102, TxHash: common.HexToHash("0x02")})
+	// 链重组撤销第二笔转账
+	view.Apply(Transfer{From: other, To: bob, Value: big.NewInt(40), Block: 102, TxHash: common.HexToHash("0x02"), Removed: true})
+
+	got := view.Balances(alice, bob)
+	if got[0].Balance.Text('f', -1) != "7.5" || got[1].Balance.Text('f', -1) != "0" || view.Block() != 102 {
+		t.Fatalf("unexpected balances: %s %s at %d", got[0].Balance.Text('f', -1), got[1].Balance.Text('f', -1), view.Block())
+	}
+}